@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Account states. Suspended accounts are locked out entirely until their
+// suspension ends; shadow-banned ones can still do everything, but their
+// chirps are only visible to themselves.
+const (
+	accountActive       = "active"
+	accountSuspended    = "suspended"
+	accountShadowBanned = "shadow_banned"
+)
+
+const maxStateReasonLength = 2000
+
+type AccountState struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	State          string     `json:"state"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Reason         string     `json:"reason"`
+	ChangedAt      *time.Time `json:"changed_at,omitempty"`
+}
+
+type AccountStateChange struct {
+	ID             uuid.UUID  `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Moderator      string     `json:"moderator"`
+	State          string     `json:"state"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Reason         string     `json:"reason"`
+}
+
+// isSuspended reports whether an account is locked out at now. Suspensions
+// with an end date lapse on their own.
+func isSuspended(state string, until sql.NullTime, now time.Time) bool {
+	return state == accountSuspended && (!until.Valid || until.Time.After(now))
+}
+
+func respondWithSuspended(w http.ResponseWriter, until sql.NullTime) {
+	msg := "Account is suspended"
+	if until.Valid {
+		msg += " until " + until.Time.UTC().Format(time.RFC3339)
+	}
+	respondWithError(w, http.StatusForbidden, msg, nil)
+}
+
+// middlewareRejectSuspended turns away requests bearing an access token of
+// a suspended account. Tokens are otherwise only checked for their
+// signature and expiry, so without this they'd keep working until they
+// expire.
+func (cfg *apiConfig) middlewareRejectSuspended(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := cfg.viewerID(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		state, err := cfg.dbQueries.GetUserAccountState(r.Context(), userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't check account state", err)
+			return
+		}
+		if err == nil && isSuspended(state.AccountState, state.SuspendedUntil, time.Now().UTC()) {
+			respondWithSuspended(w, state.SuspendedUntil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setAccountState moves an account to a new state and records who did it
+// and why. Suspending an account also revokes its refresh tokens.
+func setAccountState(ctx context.Context, q *database.Queries, userID uuid.UUID, state string, until sql.NullTime, reason, moderator string) (database.User, error) {
+	user, err := q.SetAccountState(ctx, database.SetAccountStateParams{
+		ID:             userID,
+		AccountState:   state,
+		SuspendedUntil: until,
+		StateReason:    reason,
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	_, err = q.CreateAccountStateChange(ctx, database.CreateAccountStateChangeParams{
+		UserID:         userID,
+		Moderator:      moderator,
+		AccountState:   state,
+		SuspendedUntil: until,
+		Reason:         reason,
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	if state == accountSuspended {
+		if err := q.RevokeUserRefreshTokens(ctx, userID); err != nil {
+			return database.User{}, err
+		}
+	}
+	return user, nil
+}
+
+// getAccountStateHandler shows an account's state and how it got there,
+// newest change first.
+func (cfg *apiConfig) getAccountStateHandler(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		AccountState
+		History []AccountStateChange `json:"history"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format", err)
+		return
+	}
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	changes, err := cfg.dbQueries.GetAccountStateChanges(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve account history", err)
+		return
+	}
+	resp := response{AccountState: accountStateFromDB(user), History: []AccountStateChange{}}
+	for _, change := range changes {
+		resp.History = append(resp.History, AccountStateChange{
+			ID:             change.ID,
+			CreatedAt:      change.CreatedAt,
+			Moderator:      change.Moderator,
+			State:          change.AccountState,
+			SuspendedUntil: nullTimePtr(change.SuspendedUntil),
+			Reason:         change.Reason,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// setAccountStateHandler suspends, shadow-bans or reinstates an account.
+// until is only for suspensions; without it the suspension lasts until
+// lifted.
+func (cfg *apiConfig) setAccountStateHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Moderator string     `json:"moderator"`
+		State     string     `json:"state"`
+		Until     *time.Time `json:"until"`
+		Reason    string     `json:"reason"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if params.Moderator == "" {
+		respondWithError(w, http.StatusBadRequest, "Moderator is required", nil)
+		return
+	}
+	switch params.State {
+	case accountActive, accountSuspended, accountShadowBanned:
+	default:
+		respondWithError(w, http.StatusBadRequest, "State must be active, suspended or shadow_banned", nil)
+		return
+	}
+	if params.Until != nil {
+		if params.State != accountSuspended {
+			respondWithError(w, http.StatusBadRequest, "Only suspensions can have an end date", nil)
+			return
+		}
+		if !params.Until.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "Suspension end date must be in the future", nil)
+			return
+		}
+		until := params.Until.UTC()
+		params.Until = &until
+	}
+	if params.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "Reason is required", nil)
+		return
+	}
+	if len(params.Reason) > maxStateReasonLength {
+		respondWithError(w, http.StatusBadRequest, "Reason is too long", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	user, err := setAccountState(r.Context(), qtx, userID, params.State, nullTime(params.Until), params.Reason, params.Moderator)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't change account state", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't change account state", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, accountStateFromDB(user))
+}
+
+func accountStateFromDB(user database.User) AccountState {
+	return AccountState{
+		UserID:         user.ID,
+		State:          user.AccountState,
+		SuspendedUntil: nullTimePtr(user.SuspendedUntil),
+		Reason:         user.StateReason,
+		ChangedAt:      nullTimePtr(user.StateChangedAt),
+	}
+}
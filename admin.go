@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/cygran/chirpy/internal/auth"
+)
+
+// requireAdmin checks that the request carries ADMIN_KEY in an
+// "ApiKey {key}" Authorization header and writes the error response when it
+// does not. The admin API is switched off when no key is configured.
+func (cfg *apiConfig) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if cfg.adminKey == "" {
+		respondWithError(w, http.StatusForbidden, "Admin API is disabled", nil)
+		return false
+	}
+	headerKey, err := auth.GetAPIKey(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(headerKey), []byte(cfg.adminKey)) != 1 {
+		respondWithError(w, http.StatusUnauthorized, "Invalid API Key", nil)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cygran/chirpy/internal/archive"
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	exportBatchSize = 500
+	maxImportBytes  = 64 << 20
+)
+
+// exportArchiveHandler streams the caller's profile and chirps as a zip.
+// Deleted and expired chirps and plain rechirps are left out.
+func (cfg *apiConfig) exportArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), validatedUserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	params := database.GetChirpsForExportParams{
+		UserID: validatedUserID,
+		Limit:  exportBatchSize,
+	}
+	batch, err := cfg.dbQueries.GetChirpsForExport(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+		return
+	}
+
+	// From here on the response has started, so failures can only cut the
+	// download short.
+	now := time.Now().UTC()
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="chirpy-archive-`+now.Format("2006-01-02")+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	writer, err := archive.NewWriter(w, archive.Profile{
+		ID:        user.ID,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		ChirpyRed: user.IsChirpyRed,
+	})
+	if err != nil {
+		log.Printf("Error exporting archive for user %s: %s", user.ID, err)
+		return
+	}
+	for {
+		for _, chirp := range batch {
+			if err := writer.WriteChirp(archiveChirpFromDB(chirp)); err != nil {
+				log.Printf("Error exporting archive for user %s: %s", user.ID, err)
+				return
+			}
+		}
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		params.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: last.ID, Valid: true}
+		batch, err = cfg.dbQueries.GetChirpsForExport(r.Context(), params)
+		if err != nil {
+			log.Printf("Error exporting archive for user %s: %s", user.ID, err)
+			return
+		}
+	}
+	if err := writer.Close(now); err != nil {
+		log.Printf("Error exporting archive for user %s: %s", user.ID, err)
+	}
+}
+
+func archiveChirpFromDB(chirp database.Chirp) archive.Chirp {
+	return archive.Chirp{
+		ID:            chirp.ID,
+		CreatedAt:     chirp.CreatedAt,
+		UpdatedAt:     chirp.UpdatedAt,
+		Body:          chirp.Body,
+		Visibility:    chirp.Visibility,
+		InReplyTo:     nullUUIDPtr(chirp.ParentChirpID),
+		QuotedChirpID: nullUUIDPtr(chirp.QuotedChirpID),
+		ExpiresAt:     nullTimePtr(chirp.ExpiresAt),
+	}
+}
+
+type skippedChirp struct {
+	ID     uuid.UUID `json:"id"`
+	Reason string    `json:"reason"`
+}
+
+type importedChirp struct {
+	params  database.CreateChirpParams
+	flagged []string
+	// replyTo is the parent's ID in the archive, resolved once earlier
+	// chirps have been imported.
+	replyTo *uuid.UUID
+}
+
+// importArchiveHandler recreates chirps from an export of this or another
+// instance, or from a Twitter tweets.js, keeping their original timestamps.
+// Everything is imported in one transaction. Chirps that break this
+// instance's rules are skipped and reported; chirps already imported are
+// skipped too, so the same archive can be imported again safely.
+//
+// Replies keep their parent when it's in the archive or already on this
+// instance and visible to the caller. Quotes, mentions, attachments and
+// polls aren't carried over.
+func (cfg *apiConfig) importArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Imported int            `json:"imported"`
+		Skipped  []skippedChirp `json:"skipped"`
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Archive is too large", nil)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Couldn't read archive", err)
+		return
+	}
+	parsed, err := archive.Read(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, archive.ErrUnrecognized):
+			respondWithError(w, http.StatusUnsupportedMediaType, "Expected a Chirpy archive or a Twitter tweets.js export", nil)
+		case errors.Is(err, archive.ErrTooManyChirps):
+			respondWithError(w, http.StatusBadRequest, "Archive has too many chirps", nil)
+		default:
+			respondWithError(w, http.StatusBadRequest, "Couldn't read archive", err)
+		}
+		return
+	}
+
+	// Parents are older than their replies, so oldest first means every
+	// parent in the archive exists by the time its replies are imported.
+	sort.SliceStable(parsed.Chirps, func(i, j int) bool {
+		return parsed.Chirps[i].CreatedAt.Before(parsed.Chirps[j].CreatedAt)
+	})
+	now := time.Now().UTC()
+	resp := response{Skipped: []skippedChirp{}}
+	pending := []importedChirp{}
+	for _, chirp := range parsed.Chirps {
+		if chirp.CreatedAt.IsZero() || chirp.CreatedAt.After(now) {
+			resp.Skipped = append(resp.Skipped, skippedChirp{ID: chirp.ID, Reason: "Invalid timestamp"})
+			continue
+		}
+		if chirp.ExpiresAt != nil && !chirp.ExpiresAt.After(now) {
+			resp.Skipped = append(resp.Skipped, skippedChirp{ID: chirp.ID, Reason: "Chirp has expired"})
+			continue
+		}
+		audience, err := parseAudience(chirp.Visibility, nil)
+		if err != nil {
+			resp.Skipped = append(resp.Skipped, skippedChirp{ID: chirp.ID, Reason: err.Error()})
+			continue
+		}
+		checked, violations, err := cfg.validateChirp(r.Context(), chirp.Body)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+			return
+		}
+		if len(violations) > 0 {
+			resp.Skipped = append(resp.Skipped, skippedChirp{ID: chirp.ID, Reason: violations[0].Message})
+			continue
+		}
+		pending = append(pending, importedChirp{
+			params: database.CreateChirpParams{
+				ID:         uuid.NullUUID{UUID: chirp.ID, Valid: chirp.ID != uuid.Nil},
+				CreatedAt:  sql.NullTime{Time: chirp.CreatedAt.UTC(), Valid: true},
+				Body:       checked.Body,
+				UserID:     validatedUserID,
+				Visibility: audience.Visibility,
+				ExpiresAt:  nullTime(chirp.ExpiresAt),
+			},
+			flagged: checked.Flagged,
+			replyTo: chirp.InReplyTo,
+		})
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	// Imported chirps are old news; announcing a whole archive at once
+	// would overrun every stream subscriber.
+	if err := qtx.QuietChirpEvents(r.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't import chirps", err)
+		return
+	}
+
+	// imported maps archive IDs to the chirps they became here.
+	imported := make(map[uuid.UUID]database.Chirp, len(pending))
+	for _, chirp := range pending {
+		archiveID := chirp.params.ID.UUID
+		if chirp.params.ID.Valid {
+			existing, err := qtx.ChirpsByIdIncludingDeleted(r.Context(), archiveID)
+			switch {
+			case err == nil && existing.UserID == validatedUserID:
+				imported[archiveID] = existing
+				resp.Skipped = append(resp.Skipped, skippedChirp{ID: archiveID, Reason: "Already imported"})
+				continue
+			case err == nil:
+				chirp.params.ID = uuid.NullUUID{}
+			case !errors.Is(err, sql.ErrNoRows):
+				respondWithError(w, http.StatusInternalServerError, "Couldn't import chirps", err)
+				return
+			}
+		}
+		if chirp.params.ID.Valid {
+			// Drafts and scheduled chirps keep their IDs when they're
+			// published, so those IDs are taken too.
+			reserved, err := qtx.ChirpIDReserved(r.Context(), archiveID)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Couldn't import chirps", err)
+				return
+			}
+			if reserved {
+				chirp.params.ID = uuid.NullUUID{}
+			}
+		}
+		parentID, err := importedParent(r.Context(), qtx, imported, chirp.replyTo, chirp.params.CreatedAt.Time, validatedUserID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't import chirps", err)
+			return
+		}
+		chirp.params.ParentChirpID = parentID
+		created, err := insertChirp(r.Context(), qtx, chirp.params, chirp.flagged, nil)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't import chirps", err)
+			return
+		}
+		imported[archiveID] = created
+		resp.Imported++
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't import chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// importedParent finds what an imported reply should hang off: the parent's
+// copy from this import, or the parent itself if it's already here and the
+// user can see it. Otherwise, or if the parent isn't older than the reply,
+// which threads can't show, the reply is imported as a standalone chirp.
+func importedParent(ctx context.Context, q *database.Queries, imported map[uuid.UUID]database.Chirp, replyTo *uuid.UUID, createdAt time.Time, userID uuid.UUID) (uuid.NullUUID, error) {
+	if replyTo == nil {
+		return uuid.NullUUID{}, nil
+	}
+	if parent, ok := imported[*replyTo]; ok {
+		if !parent.CreatedAt.Before(createdAt) {
+			return uuid.NullUUID{}, nil
+		}
+		return uuid.NullUUID{UUID: parent.ID, Valid: true}, nil
+	}
+	parent, err := q.ChirpsById(ctx, *replyTo)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.NullUUID{}, nil
+	}
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if parent.RechirpOfID.Valid || parent.TombstonedAt.Valid || !parent.CreatedAt.Before(createdAt) {
+		return uuid.NullUUID{}, nil
+	}
+	visible, err := canViewChirp(ctx, q, parent, userID)
+	if err != nil || !visible {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: parent.ID, Valid: true}, nil
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/media"
+	"github.com/cygran/chirpy/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	maxAttachmentsPerChirp = 4
+	maxAltTextLength       = 1000
+	// Uploads that haven't been attached to a chirp within this long are
+	// assumed abandoned.
+	orphanedAttachmentAge     = 24 * time.Hour
+	attachmentCleanupInterval = time.Hour
+	attachmentCleanupBatch    = 500
+	// How long caches may reuse shareable media before checking it is
+	// still visible. Hiding, deleting or narrowing a chirp takes effect
+	// within this long.
+	sharedMediaCacheControl = "public, max-age=60, must-revalidate"
+)
+
+type Attachment struct {
+	ID           uuid.UUID `json:"id"`
+	ContentType  string    `json:"content_type"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	SizeBytes    int32     `json:"size_bytes"`
+	AltText      string    `json:"alt_text"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+}
+
+func attachmentFromDB(attachment database.Attachment) Attachment {
+	url := "/api/media/" + attachment.ID.String()
+	return Attachment{
+		ID:           attachment.ID,
+		ContentType:  attachment.ContentType,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+		SizeBytes:    attachment.SizeBytes,
+		AltText:      attachment.AltText,
+		URL:          url,
+		ThumbnailURL: url + "/thumbnail",
+	}
+}
+
+// uploadMediaHandler takes a multipart upload with the image in "file" and
+// optional "alt_text". The upload stays unattached until it's used in a chirp.
+func (cfg *apiConfig) uploadMediaHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+
+	// Leave some room for the multipart framing and the alt text.
+	r.Body = http.MaxBytesReader(w, r.Body, media.MaxBytes+64<<10)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "File is too large", nil)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file", err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, media.MaxBytes+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file", err)
+		return
+	}
+	if len(data) > media.MaxBytes {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "File is too large", nil)
+		return
+	}
+	altText := r.FormValue("alt_text")
+	if utf8.RuneCountInString(altText) > maxAltTextLength {
+		respondWithError(w, http.StatusBadRequest, "Alt text is too long", nil)
+		return
+	}
+
+	img, err := media.Process(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupportedType):
+			respondWithError(w, http.StatusUnsupportedMediaType, "Only JPEG, PNG, GIF and WebP images are supported", nil)
+		case errors.Is(err, media.ErrTooManyPixels):
+			respondWithError(w, http.StatusBadRequest, "Image dimensions are too large", nil)
+		default:
+			respondWithError(w, http.StatusBadRequest, "Couldn't decode image", err)
+		}
+		return
+	}
+
+	id := uuid.New()
+	storageKey := "media/" + id.String()
+	thumbnailKey := storageKey + "-thumb"
+	if err := cfg.media.Put(r.Context(), storageKey, bytes.NewReader(data)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store file", err)
+		return
+	}
+	if err := cfg.media.Put(r.Context(), thumbnailKey, bytes.NewReader(img.Thumbnail)); err != nil {
+		cfg.deleteStoredFiles(r.Context(), storageKey)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store file", err)
+		return
+	}
+	attachment, err := cfg.dbQueries.CreateAttachment(r.Context(), database.CreateAttachmentParams{
+		ID:                   id,
+		UserID:               validatedUserID,
+		ContentType:          img.ContentType,
+		SizeBytes:            int32(len(data)),
+		Width:                int32(img.Width),
+		Height:               int32(img.Height),
+		StorageKey:           storageKey,
+		ThumbnailKey:         thumbnailKey,
+		ThumbnailContentType: img.ThumbnailContentType,
+		AltText:              altText,
+	})
+	if err != nil {
+		cfg.deleteStoredFiles(r.Context(), storageKey, thumbnailKey)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save attachment", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, attachmentFromDB(attachment))
+}
+
+func (cfg *apiConfig) getMediaHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.serveMedia(w, r, false)
+}
+
+func (cfg *apiConfig) getMediaThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.serveMedia(w, r, true)
+}
+
+func (cfg *apiConfig) serveMedia(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	idString := r.PathValue("mediaID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid media ID format", err)
+		return
+	}
+	attachment, err := cfg.dbQueries.GetAttachment(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Media not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	// The chirp it belonged to is gone; the file is only waiting for cleanup.
+	if attachment.AttachedAt.Valid && !attachment.ChirpID.Valid {
+		respondWithError(w, http.StatusNotFound, "Media not found", nil)
+		return
+	}
+	// Attached media is only as visible as its chirp, which can change at
+	// any time, so caches must keep checking back. Unattached uploads are
+	// only of use to their uploader until they're attached.
+	cacheControl := "private, no-cache"
+	if attachment.ChirpID.Valid {
+		chirp, err := cfg.viewableChirp(r, cfg.dbQueries, attachment.ChirpID.UUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Media not found", nil)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+			return
+		}
+		// Authors also see their chirps after a moderator has hidden them or
+		// shadow-banned the account, so their copies mustn't be shared.
+		viewerID, _ := cfg.viewerID(r)
+		if amplifiable(chirp) && chirp.UserID != viewerID {
+			cacheControl = sharedMediaCacheControl
+		}
+	}
+
+	key, contentType := attachment.StorageKey, attachment.ContentType
+	etag := `"` + attachment.ID.String() + `"`
+	if thumbnail {
+		key, contentType = attachment.ThumbnailKey, attachment.ThumbnailContentType
+		etag = `"` + attachment.ID.String() + `-thumbnail"`
+	}
+	// Stored files never change, so revalidating only needs the checks
+	// above, not the file.
+	if notModified(r, etag, time.Time{}) {
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	file, err := cfg.media.Open(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			respondWithError(w, http.StatusNotFound, "Media not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't open file", err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("Error serving media %s: %s", id, err)
+	}
+}
+
+type attachmentParameters struct {
+	MediaID uuid.UUID `json:"media_id"`
+	AltText *string   `json:"alt_text"`
+}
+
+var errAttachmentUnavailable = errors.New("Attachment not found or already used")
+
+// checkAttachments validates the attachments requested for a new chirp
+// before anything is written.
+func checkAttachments(attachments []attachmentParameters) error {
+	if len(attachments) > maxAttachmentsPerChirp {
+		return errors.New("Too many attachments")
+	}
+	seen := make(map[uuid.UUID]bool, len(attachments))
+	for _, attachment := range attachments {
+		if seen[attachment.MediaID] {
+			return errors.New("Duplicate attachment")
+		}
+		seen[attachment.MediaID] = true
+		if attachment.AltText != nil && utf8.RuneCountInString(*attachment.AltText) > maxAltTextLength {
+			return errors.New("Alt text is too long")
+		}
+	}
+	return nil
+}
+
+// attachMedia claims the user's unattached uploads for a chirp, in the order
+// given. Alt text sent with the chirp replaces any given at upload time.
+func attachMedia(ctx context.Context, q *database.Queries, chirpID, userID uuid.UUID, attachments []attachmentParameters) error {
+	for i, attachment := range attachments {
+		altText := sql.NullString{}
+		if attachment.AltText != nil {
+			altText = sql.NullString{String: *attachment.AltText, Valid: true}
+		}
+		attached, err := q.AttachToChirp(ctx, database.AttachToChirpParams{
+			ChirpID:  uuid.NullUUID{UUID: chirpID, Valid: true},
+			Position: int32(i),
+			AltText:  altText,
+			ID:       attachment.MediaID,
+			UserID:   userID,
+		})
+		if err != nil {
+			return err
+		}
+		if attached == 0 {
+			return errAttachmentUnavailable
+		}
+	}
+	return nil
+}
+
+// embedAttachments fills in the attachments of chirps and of the chirps they
+// rechirp or quote.
+func (cfg *apiConfig) embedAttachments(ctx context.Context, chirps []Chirp) error {
+	targets := visibleChirps(chirps)
+	if len(targets) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(targets))
+	for _, chirp := range targets {
+		ids = append(ids, chirp.ID)
+	}
+	dbAttachments, err := cfg.dbQueries.GetChirpAttachments(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byChirp := make(map[uuid.UUID][]Attachment)
+	for _, attachment := range dbAttachments {
+		byChirp[attachment.ChirpID.UUID] = append(byChirp[attachment.ChirpID.UUID], attachmentFromDB(attachment))
+	}
+	for _, chirp := range targets {
+		chirp.Attachments = byChirp[chirp.ID]
+	}
+	return nil
+}
+
+// cleanUpAttachmentsLoop removes abandoned uploads every interval until ctx
+// is done.
+func (cfg *apiConfig) cleanUpAttachmentsLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		removed, err := cfg.cleanUpAttachments(ctx)
+		if err != nil {
+			log.Printf("Error cleaning up attachments: %s", err)
+		} else if removed > 0 {
+			log.Printf("Removed %d orphaned attachments", removed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanUpAttachments deletes attachments that were never used, or whose
+// chirp has been deleted, along with their files. Rows go first: a file left
+// behind by a failed delete is only wasted space, while a row without its
+// file would be served as broken.
+func (cfg *apiConfig) cleanUpAttachments(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		orphaned, err := cfg.dbQueries.DeleteOrphanedAttachments(ctx, database.DeleteOrphanedAttachmentsParams{
+			CreatedBefore: time.Now().UTC().Add(-orphanedAttachmentAge),
+			Limit:         attachmentCleanupBatch,
+		})
+		if err != nil {
+			return removed, err
+		}
+		for _, attachment := range orphaned {
+			cfg.deleteStoredFiles(ctx, attachment.StorageKey, attachment.ThumbnailKey)
+		}
+		removed += len(orphaned)
+		if len(orphaned) < attachmentCleanupBatch {
+			return removed, nil
+		}
+	}
+}
+
+func (cfg *apiConfig) deleteStoredFiles(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := cfg.media.Delete(ctx, key); err != nil {
+			log.Printf("Error deleting stored file %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/stream"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	chirpStreamBuffer      = 64
+	chirpStreamHeartbeat   = 15 * time.Second
+	chirpStreamReplayLimit = 1000
+	chirpEventPollInterval = 5 * time.Second
+	chirpEventBatchSize    = 500
+	chirpEventRetention    = time.Hour
+	chirpEventPruneEvery   = 10 * time.Minute
+	// Event IDs are handed out before their transaction commits, so a
+	// lower ID can show up after a higher one. Skipped IDs are looked for
+	// again until this long has passed; most are just rolled back.
+	chirpEventGapTimeout = time.Minute
+	maxTrackedEventGap   = 1000
+)
+
+// streamChirpsHandler pushes public chirps as they're posted and deleted,
+// as Server-Sent Events:
+//
+//	event: chirp_created, data: the chirp as GET /api/chirps shows it
+//	event: chirp_deleted, data: {"id": ..., "user_id": ...}
+//
+// author_id limits the stream to one author. Reconnecting clients send
+// Last-Event-ID to get what they missed, as far back as the event log goes.
+// Events arrive at least once and almost always in order; clients should
+// dedupe by chirp ID. A stream opened with an access token is closed at the
+// next heartbeat after its account is suspended; reconnecting then gets a
+// 403.
+func (cfg *apiConfig) streamChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	authorID := uuid.Nil
+	if idString := r.URL.Query().Get("author_id"); idString != "" {
+		parsed, err := uuid.Parse(idString)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid author_id", err)
+			return
+		}
+		authorID = parsed
+	}
+	lastEventID := int64(-1)
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		parsed, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid Last-Event-ID", err)
+			return
+		}
+		lastEventID = parsed
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "Streaming unsupported", nil)
+		return
+	}
+
+	// Subscribe before replaying so nothing falls between the two.
+	sub := cfg.chirpStream.Subscribe(authorID)
+	defer sub.Close()
+	replayed := map[int64]bool{}
+	replay := []stream.Event{}
+	if lastEventID >= 0 {
+		events, err := cfg.dbQueries.GetChirpEventsAfter(r.Context(), database.GetChirpEventsAfterParams{
+			AfterID: lastEventID,
+			Limit:   chirpStreamReplayLimit,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve events", err)
+			return
+		}
+		for _, event := range events {
+			if authorID != uuid.Nil && event.UserID != authorID {
+				continue
+			}
+			e, ok, err := cfg.chirpStreamEvent(r.Context(), event)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve events", err)
+				return
+			}
+			if ok {
+				replay = append(replay, e)
+			}
+			replayed[event.ID] = true
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop proxies from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	for _, e := range replay {
+		writeStreamEvent(w, e)
+	}
+	flusher.Flush()
+
+	viewerID, signedIn := cfg.viewerID(r)
+	heartbeat := time.NewTicker(chirpStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind. The client reconnects with
+				// Last-Event-ID and catches up from the log.
+				fmt.Fprint(w, ": too slow, reconnect\n\n")
+				flusher.Flush()
+				return
+			}
+			if replayed[e.ID] {
+				continue
+			}
+			writeStreamEvent(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			if signedIn && cfg.streamViewerSuspended(r.Context(), viewerID) {
+				fmt.Fprint(w, ": account suspended\n\n")
+				flusher.Flush()
+				return
+			}
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// streamViewerSuspended re-checks the account behind an open stream, which
+// middlewareRejectSuspended only saw when it connected. A deleted account
+// counts as suspended. A failed lookup keeps the stream open rather than
+// dropping every client on a database hiccup.
+func (cfg *apiConfig) streamViewerSuspended(ctx context.Context, userID uuid.UUID) bool {
+	state, err := cfg.dbQueries.GetUserAccountState(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	if err != nil {
+		log.Printf("Error checking account state of stream viewer %s: %s", userID, err)
+		return false
+	}
+	return isSuspended(state.AccountState, state.SuspendedUntil, time.Now().UTC())
+}
+
+func writeStreamEvent(w http.ResponseWriter, e stream.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Name, e.Data)
+}
+
+// chirpStreamEvent renders a logged event for the stream. Created chirps
+// that are already gone again are skipped.
+func (cfg *apiConfig) chirpStreamEvent(ctx context.Context, event database.ChirpEvent) (stream.Event, bool, error) {
+	e := stream.Event{ID: event.ID, AuthorID: event.UserID}
+	switch event.Kind {
+	case "created":
+		dbChirp, err := cfg.dbQueries.ChirpsById(ctx, event.ChirpID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return stream.Event{}, false, nil
+		}
+		if err != nil {
+			return stream.Event{}, false, err
+		}
+		if dbChirp.Visibility != visibilityPublic {
+			return stream.Event{}, false, nil
+		}
+		chirps := []Chirp{chirpFromDB(dbChirp)}
+		if err := cfg.decorateChirpsFor(ctx, uuid.Nil, chirps); err != nil {
+			return stream.Event{}, false, err
+		}
+		e.Name = "chirp_created"
+		e.Data, err = json.Marshal(chirps[0])
+		if err != nil {
+			return stream.Event{}, false, err
+		}
+	case "deleted":
+		type deleted struct {
+			ID     uuid.UUID `json:"id"`
+			UserID uuid.UUID `json:"user_id"`
+		}
+		data, err := json.Marshal(deleted{ID: event.ChirpID, UserID: event.UserID})
+		if err != nil {
+			return stream.Event{}, false, err
+		}
+		e.Name = "chirp_deleted"
+		e.Data = data
+	default:
+		return stream.Event{}, false, nil
+	}
+	return e, true, nil
+}
+
+// followChirpEvents publishes new chirp events to this instance's stream
+// subscribers until ctx is done. NOTIFY only wakes it up; the event log is
+// read on every wake-up and every poll interval, so notifications lost
+// while reconnecting cost nothing but a short delay.
+func (cfg *apiConfig) followChirpEvents(ctx context.Context, dbURL string) {
+	listener := pq.NewListener(dbURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Chirp event listener: %s", err)
+		}
+	})
+	defer listener.Close()
+	if err := listener.Listen("chirp_events"); err != nil {
+		log.Printf("Error listening for chirp events: %s", err)
+	}
+
+	lastID, err := cfg.dbQueries.GetLatestChirpEventID(ctx)
+	for err != nil {
+		log.Printf("Error reading chirp events: %s", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(chirpEventPollInterval):
+		}
+		lastID, err = cfg.dbQueries.GetLatestChirpEventID(ctx)
+	}
+	follower := &chirpEventFollower{lastID: lastID, pending: map[int64]time.Time{}}
+
+	poll := time.NewTicker(chirpEventPollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(chirpEventPruneEvery)
+	defer prune.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.NotificationChannel():
+		case <-poll.C:
+		case <-prune.C:
+			err := cfg.dbQueries.DeleteChirpEventsBefore(ctx, time.Now().UTC().Add(-chirpEventRetention))
+			if err != nil {
+				log.Printf("Error pruning chirp events: %s", err)
+			}
+			continue
+		}
+		if err := cfg.publishChirpEvents(ctx, follower); err != nil {
+			log.Printf("Error publishing chirp events: %s", err)
+		}
+	}
+}
+
+// chirpEventFollower tracks how far this instance has read the event log.
+type chirpEventFollower struct {
+	lastID int64
+	// pending holds skipped IDs that may still commit, with when they were
+	// first missed.
+	pending map[int64]time.Time
+}
+
+func (cfg *apiConfig) publishChirpEvents(ctx context.Context, f *chirpEventFollower) error {
+	for {
+		now := time.Now()
+		pendingIDs := make([]int64, 0, len(f.pending))
+		for id, missedAt := range f.pending {
+			if now.Sub(missedAt) > chirpEventGapTimeout {
+				delete(f.pending, id)
+				continue
+			}
+			pendingIDs = append(pendingIDs, id)
+		}
+		events, err := cfg.dbQueries.GetChirpEventsAfter(ctx, database.GetChirpEventsAfterParams{
+			AfterID:    f.lastID,
+			PendingIds: pendingIDs,
+			Limit:      chirpEventBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if _, ok := f.pending[event.ID]; ok {
+				delete(f.pending, event.ID)
+			} else if event.ID > f.lastID {
+				if event.ID-f.lastID-1 <= maxTrackedEventGap {
+					for id := f.lastID + 1; id < event.ID; id++ {
+						f.pending[id] = now
+					}
+				}
+				f.lastID = event.ID
+			}
+			e, ok, err := cfg.chirpStreamEvent(ctx, event)
+			if err != nil {
+				return err
+			}
+			if ok {
+				cfg.chirpStream.Publish(e)
+			}
+		}
+		if len(events) < chirpEventBatchSize {
+			return nil
+		}
+	}
+}
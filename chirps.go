@@ -1,29 +1,59 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/cygran/chirpy/internal/auth"
 	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/validation"
 	"github.com/google/uuid"
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    uuid.UUID `json:"user_id"`
-	Body      string    `json:"body"`
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Author        *Profile   `json:"author,omitempty"`
+	Body          string     `json:"body"`
+	Edited        bool       `json:"edited"`
+	InReplyTo     *uuid.UUID `json:"in_reply_to"`
+	RootChirpID   *uuid.UUID `json:"root_chirp_id"`
+	ReplyCount    int32      `json:"reply_count"`
+	Tombstone     bool       `json:"tombstone"`
+	LikeCount     int32      `json:"like_count"`
+	LikedByMe     *bool      `json:"liked_by_me,omitempty"`
+	RechirpOfID   *uuid.UUID `json:"rechirp_of_id"`
+	RechirpOf     *Chirp     `json:"rechirp_of,omitempty"`
+	QuotedChirpID *uuid.UUID `json:"quoted_chirp_id"`
+	QuotedChirp   *Chirp     `json:"quoted_chirp,omitempty"`
+	RechirpCount  int32      `json:"rechirp_count"`
+	QuoteCount    int32      `json:"quote_count"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	Visibility    string     `json:"visibility"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	// ModerationNotice tells the author why nobody else can see the chirp.
+	ModerationNotice string       `json:"moderation_notice,omitempty"`
+	Attachments      []Attachment `json:"attachments,omitempty"`
+	LinkPreview      *LinkPreview `json:"link_preview,omitempty"`
+	Poll             *Poll        `json:"poll,omitempty"`
 }
 
 func (cfg *apiConfig) createChirpHandler(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Body string `json:"body"`
+		Body        string                 `json:"body"`
+		InReplyTo   *uuid.UUID             `json:"in_reply_to"`
+		PublishAt   *time.Time             `json:"publish_at"`
+		Attachments []attachmentParameters `json:"attachments"`
+		Poll        *pollParameters        `json:"poll"`
+		Visibility  string                 `json:"visibility"`
+		Mentions    []uuid.UUID            `json:"mentions"`
+		ExpiresIn   *int64                 `json:"expires_in"`
 	}
 	token, err := auth.GetBearerToken(r.Header)
 	if err != nil {
@@ -43,55 +73,337 @@ func (cfg *apiConfig) createChirpHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	cleaned, err := validateChirp(params.Body)
+	checked, violations, err := cfg.validateChirp(r.Context(), params.Body)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+		return
+	}
+	if len(violations) > 0 {
+		respondWithViolations(w, violations)
 		return
 	}
 
-	chirp, err := cfg.dbQueries.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   cleaned,
-		UserID: tokenUUID,
-	})
+	audience, err := parseAudience(params.Visibility, params.Mentions)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if err := checkAttachments(params.Attachments); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if params.Poll != nil {
+		if err := checkPoll(params.Poll, time.Now().UTC()); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+	}
+
+	expiresAt := sql.NullTime{}
+	if params.ExpiresIn != nil {
+		user, err := cfg.dbQueries.GetUserByID(r.Context(), tokenUUID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+			return
+		}
+		if err := checkChirpLifetime(*params.ExpiresIn, user.IsChirpyRed); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		expiresAt = sql.NullTime{Time: time.Now().UTC().Add(time.Duration(*params.ExpiresIn) * time.Second), Valid: true}
+	}
+
+	parentID := nullUUID(params.InReplyTo)
+	if params.PublishAt != nil {
+		if len(params.Attachments) > 0 || params.Poll != nil || expiresAt.Valid {
+			respondWithError(w, http.StatusBadRequest, "Scheduled chirps can't have attachments, polls or an expiry", nil)
+			return
+		}
+		cfg.scheduleChirp(w, r, tokenUUID, parentID, checked, audience, *params.PublishAt)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
 	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := insertChirp(r.Context(), qtx, database.CreateChirpParams{
+		Body:          checked.Body,
+		UserID:        tokenUUID,
+		ParentChirpID: parentID,
+		Visibility:    audience.Visibility,
+		ExpiresAt:     expiresAt,
+	}, checked.Flagged, audience.Mentions)
+	if err == nil {
+		err = attachMedia(r.Context(), qtx, chirp.ID, tokenUUID, params.Attachments)
+	}
+	if err == nil && params.Poll != nil {
+		err = createPoll(r.Context(), qtx, chirp.ID, *params.Poll)
+	}
+	if err == nil {
+		err = fanOutChirp(r.Context(), qtx, chirp)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errParentNotFound):
+			respondWithError(w, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, errReplyToRechirp), errors.Is(err, errAttachmentUnavailable):
+			respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
-	})
+	cfg.queueLinkPreview(chirp)
+
+	chirps := []Chirp{chirpFromDB(chirp)}
+	if err := cfg.embedAttachments(r.Context(), chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve attachments", err)
+		return
+	}
+	if err := cfg.embedPolls(r.Context(), tokenUUID, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve poll", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, chirps[0])
 }
 
-func validateChirp(body string) (string, error) {
-	const maxChirpLength = 140
-	if len(body) > maxChirpLength {
-		return "", errors.New("Chirp is too long")
+var (
+	errParentNotFound = errors.New("Parent chirp not found")
+	errReplyToRechirp = errors.New("Reply to the original chirp instead of the rechirp")
+)
+
+// insertChirp creates a chirp and records its hashtags, flagged words and
+// mentions. When params.ParentChirpID is set the chirp is a reply: the
+// parent, which the author must be able to see, is locked, its reply count
+// bumped and the thread root filled in.
+func insertChirp(ctx context.Context, q *database.Queries, params database.CreateChirpParams, flagged []string, mentions []uuid.UUID) (database.Chirp, error) {
+	if params.ParentChirpID.Valid {
+		parent, err := q.ChirpsByIdForUpdate(ctx, params.ParentChirpID.UUID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Chirp{}, errParentNotFound
+		}
+		if err != nil {
+			return database.Chirp{}, err
+		}
+		visible, err := canViewChirp(ctx, q, parent, params.UserID)
+		if err != nil {
+			return database.Chirp{}, err
+		}
+		if !visible {
+			return database.Chirp{}, errParentNotFound
+		}
+		if parent.RechirpOfID.Valid {
+			return database.Chirp{}, errReplyToRechirp
+		}
+		params.RootChirpID = parent.RootChirpID
+		if !params.RootChirpID.Valid {
+			params.RootChirpID = params.ParentChirpID
+		}
+		if err := q.IncrementReplyCount(ctx, parent.ID); err != nil {
+			return database.Chirp{}, err
+		}
+	}
+
+	chirp, err := q.CreateChirp(ctx, params)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if err := tagChirp(ctx, q, chirp); err != nil {
+		return database.Chirp{}, err
 	}
+	if err := flagChirp(ctx, q, chirp.ID, flagged); err != nil {
+		return database.Chirp{}, err
+	}
+	if len(mentions) > 0 {
+		err := q.AddChirpMentions(ctx, database.AddChirpMentionsParams{
+			ChirpID: chirp.ID,
+			UserIds: mentions,
+		})
+		if err != nil {
+			return database.Chirp{}, err
+		}
+	}
+	return chirp, nil
+}
 
-	badWords := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+// chirpFromDB builds the public view of a chirp. Deleted and expired chirps
+// only turn up as placeholders in threads, so their content is left out.
+func chirpFromDB(chirp database.Chirp) Chirp {
+	c := fullChirpFromDB(chirp)
+	if chirp.DeletedAt.Valid || chirpExpired(chirp) {
+		c.Body = ""
+		c.QuotedChirpID = nil
+		c.Tombstone = true
+		c.DeletedAt = nil
+		c.ExpiresAt = nil
+		c.ModerationNotice = ""
 	}
-	cleaned := getCleanedBody(body, badWords)
-	return cleaned, nil
+	return c
 }
 
-func getCleanedBody(body string, badWords map[string]struct{}) string {
-	words := strings.Split(body, " ")
-	for i, word := range words {
-		loweredWord := strings.ToLower(word)
-		if _, ok := badWords[loweredWord]; ok {
-			words[i] = "****"
+// fullChirpFromDB keeps the content of deleted chirps, for their author's
+// trash and for moderators.
+func fullChirpFromDB(chirp database.Chirp) Chirp {
+	c := Chirp{
+		ID:            chirp.ID,
+		CreatedAt:     chirp.CreatedAt,
+		UpdatedAt:     chirp.UpdatedAt,
+		Body:          chirp.Body,
+		UserID:        chirp.UserID,
+		Edited:        chirp.EditedAt.Valid,
+		InReplyTo:     nullUUIDPtr(chirp.ParentChirpID),
+		RootChirpID:   nullUUIDPtr(chirp.RootChirpID),
+		ReplyCount:    chirp.ReplyCount,
+		Tombstone:     chirp.TombstonedAt.Valid,
+		LikeCount:     chirp.LikeCount,
+		RechirpOfID:   nullUUIDPtr(chirp.RechirpOfID),
+		QuotedChirpID: nullUUIDPtr(chirp.QuotedChirpID),
+		RechirpCount:  chirp.RechirpCount,
+		QuoteCount:    chirp.QuoteCount,
+		DeletedAt:     nullTimePtr(chirp.DeletedAt),
+		Visibility:    chirp.Visibility,
+		ExpiresAt:     nullTimePtr(chirp.ExpiresAt),
+	}
+	if chirp.HiddenAt.Valid {
+		c.ModerationNotice = hiddenChirpNotice
+	}
+	return c
+}
+
+// decorateChirps fills in the parts of the Chirp JSON that live outside the
+// chirp's own row, plus the authors' profiles if the request asks for them.
+func (cfg *apiConfig) decorateChirps(r *http.Request, chirps []Chirp) error {
+	viewerID, _ := cfg.viewerID(r)
+	if err := cfg.decorateChirpsFor(r.Context(), viewerID, chirps); err != nil {
+		return err
+	}
+	if wantsAuthors(r) {
+		return cfg.embedAuthors(r.Context(), chirps)
+	}
+	return nil
+}
+
+// decorateChirpsFor is decorateChirps outside a request. viewerID is
+// uuid.Nil for an anonymous viewer.
+func (cfg *apiConfig) decorateChirpsFor(ctx context.Context, viewerID uuid.UUID, chirps []Chirp) error {
+	if err := cfg.embedReferencedChirps(ctx, viewerID, chirps); err != nil {
+		return err
+	}
+	if err := cfg.embedAttachments(ctx, chirps); err != nil {
+		return err
+	}
+	if err := cfg.embedLinkPreviews(ctx, chirps); err != nil {
+		return err
+	}
+	if err := cfg.embedPolls(ctx, viewerID, chirps); err != nil {
+		return err
+	}
+	if viewerID != uuid.Nil {
+		return cfg.markLikedByViewer(ctx, viewerID, chirps)
+	}
+	return nil
+}
+
+// visibleChirps returns the chirps, and the chirps they rechirp or quote,
+// that still have content to decorate. Tombstones have nothing left to show.
+func visibleChirps(chirps []Chirp) []*Chirp {
+	visible := []*Chirp{}
+	for i := range chirps {
+		for _, chirp := range []*Chirp{&chirps[i], chirps[i].RechirpOf, chirps[i].QuotedChirp} {
+			if chirp != nil && !chirp.Tombstone {
+				visible = append(visible, chirp)
+			}
 		}
 	}
-	cleaned := strings.Join(words, " ")
-	return cleaned
+	return visible
+}
+
+// latestUpdate is the Last-Modified of a set of chirps, counting the chirps
+// they embed and their authors' profiles. A poll closes without a write, so
+// a closed poll counts from when it expired.
+func latestUpdate(chirps []Chirp) time.Time {
+	latest := time.Time{}
+	for _, chirp := range chirps {
+		for _, c := range []*Chirp{&chirp, chirp.RechirpOf, chirp.QuotedChirp} {
+			if c == nil {
+				continue
+			}
+			if c.UpdatedAt.After(latest) {
+				latest = c.UpdatedAt
+			}
+			if c.Author != nil && c.Author.updatedAt.After(latest) {
+				latest = c.Author.updatedAt
+			}
+			if c.Poll != nil && c.Poll.Closed && c.Poll.ExpiresAt.After(latest) {
+				latest = c.Poll.ExpiresAt
+			}
+		}
+	}
+	return latest
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// validateChirp runs body through the deployment's validation rules with
+// the current word filter. The returned chirp holds the normalized body and
+// any flagged words for the caller to record once the chirp exists.
+func (cfg *apiConfig) validateChirp(ctx context.Context, body string) (validation.Chirp, []validation.Violation, error) {
+	filter, err := cfg.wordFilter(ctx)
+	if err != nil {
+		return validation.Chirp{}, nil, err
+	}
+	chirp := validation.Chirp{Body: body}
+	violations := cfg.chirpValidation.Build(filter).Validate(&chirp)
+	return chirp, violations, nil
+}
+
+// respondWithViolations reports every rule a chirp broke. The error field
+// carries the first message so older clients still get something readable.
+func respondWithViolations(w http.ResponseWriter, violations []validation.Violation) {
+	type errorResponse struct {
+		Error      string                 `json:"error"`
+		Violations []validation.Violation `json:"violations"`
+	}
+	respondWithJSON(w, http.StatusBadRequest, errorResponse{
+		Error:      violations[0].Message,
+		Violations: violations,
+	})
 }
 
 func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request) {
@@ -111,7 +423,15 @@ func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
 		return
 	}
-	chirp, err := cfg.dbQueries.ChirpsById(r.Context(), id)
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := qtx.ChirpsByIdForUpdate(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
@@ -120,10 +440,150 @@ func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
 		return
 	}
+	if chirp.TombstonedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
 	if chirp.UserID != validatedUserID {
-		respondWithError(w, http.StatusForbidden, "You cannot delete someone elses chirp", err)
+		respondWithNotYourChirp(w, r, qtx, chirp, validatedUserID, "You cannot delete someone elses chirp")
+		return
+	}
+	err = adjustChirpReferences(r.Context(), qtx, chirp, -1)
+	if err == nil {
+		if chirp.RechirpOfID.Valid {
+			// Rechirps have no content worth keeping in the trash.
+			err = removeChirp(r.Context(), qtx, chirp)
+		} else {
+			err = softDeleteChirp(r.Context(), qtx, chirp.ID)
+		}
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp", err)
 		return
 	}
-	cfg.dbQueries.DeleteChrip(r.Context(), id)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// softDeleteChirp moves a chirp to the trash. Its content stays so it can be
+// restored, but it stops counting towards hashtags and its parent's replies,
+// and its plain rechirps go.
+func softDeleteChirp(ctx context.Context, q *database.Queries, id uuid.UUID) error {
+	chirp, err := q.SoftDeleteChirp(ctx, id)
+	if err != nil {
+		return err
+	}
+	if chirp.ParentChirpID.Valid {
+		_, err := q.DecrementReplyCount(ctx, chirp.ParentChirpID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+	if err := q.ClearChirpHashtags(ctx, id); err != nil {
+		return err
+	}
+	return q.DeleteRechirpsOf(ctx, uuid.NullUUID{UUID: id, Valid: true})
+}
+
+// tombstoneChirp keeps a chirp's row so its replies still hang off it, but
+// drops everything the author wrote along with any plain rechirps of it.
+// Detached attachments are left for the cleanup loop to delete.
+func tombstoneChirp(ctx context.Context, q *database.Queries, id uuid.UUID) error {
+	if _, err := q.TombstoneChirp(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DetachChirpAttachments(ctx, uuid.NullUUID{UUID: id, Valid: true}); err != nil {
+		return err
+	}
+	if err := q.DeleteLinkPreview(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeletePoll(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeleteChirpRevisions(ctx, id); err != nil {
+		return err
+	}
+	if err := q.ClearChirpHashtags(ctx, id); err != nil {
+		return err
+	}
+	return q.DeleteRechirpsOf(ctx, uuid.NullUUID{UUID: id, Valid: true})
+}
+
+// destroyChirp removes a chirp for good, whether or not it's in the trash.
+// Chirps that still have replies are tombstoned instead, and ones already
+// tombstoned are left alone.
+func destroyChirp(ctx context.Context, q *database.Queries, id uuid.UUID) error {
+	chirp, err := q.ChirpsByIdIncludingDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+	if chirp.TombstonedAt.Valid {
+		return nil
+	}
+	// Chirps in the trash have already given back their references.
+	if !chirp.DeletedAt.Valid {
+		if err := adjustChirpReferences(ctx, q, chirp, -1); err != nil {
+			return err
+		}
+	}
+	hasReplies, err := chirpHasReplies(ctx, q, chirp.ID)
+	if err != nil {
+		return err
+	}
+	if !hasReplies {
+		return removeChirp(ctx, q, chirp)
+	}
+	if !chirp.DeletedAt.Valid {
+		if err := softDeleteChirp(ctx, q, chirp.ID); err != nil {
+			return err
+		}
+	}
+	return tombstoneChirp(ctx, q, chirp.ID)
+}
+
+// removeChirp hard-deletes a chirp without replies, then walks up the thread
+// removing tombstoned ancestors that no longer have any replies left.
+func removeChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	for {
+		if err := q.DeleteChrip(ctx, chirp.ID); err != nil {
+			return err
+		}
+		if !chirp.ParentChirpID.Valid {
+			return nil
+		}
+		var parent database.Chirp
+		var err error
+		// Deleted chirps, tombstones included, already left their parent's
+		// reply count when they went to the trash.
+		if chirp.DeletedAt.Valid {
+			parent, err = q.ChirpsByIdIncludingDeleted(ctx, chirp.ParentChirpID.UUID)
+		} else {
+			parent, err = q.DecrementReplyCount(ctx, chirp.ParentChirpID.UUID)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !parent.TombstonedAt.Valid {
+			return nil
+		}
+		hasReplies, err := chirpHasReplies(ctx, q, parent.ID)
+		if err != nil || hasReplies {
+			return err
+		}
+		chirp = parent
+	}
+}
+
+// chirpHasReplies reports whether any chirp still replies to id, including
+// replies in the trash, which reply_count leaves out but which may yet be
+// restored.
+func chirpHasReplies(ctx context.Context, q *database.Queries, id uuid.UUID) (bool, error) {
+	return q.ChirpHasReplies(ctx, uuid.NullUUID{UUID: id, Valid: true})
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/validation"
+	"github.com/google/uuid"
+)
+
+// maxDraftBytes bounds what we store for a draft. Drafts may run over the
+// chirp limits while they're being written, just not without end.
+const maxDraftBytes = 4096
+
+// Draft is an unpublished chirp. Warnings lists what would stop it from
+// being published as it stands.
+type Draft struct {
+	ID         uuid.UUID              `json:"id"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	UserID     uuid.UUID              `json:"user_id"`
+	Body       string                 `json:"body"`
+	InReplyTo  *uuid.UUID             `json:"in_reply_to"`
+	Visibility string                 `json:"visibility"`
+	Mentions   []uuid.UUID            `json:"mentions"`
+	Warnings   []validation.Violation `json:"warnings"`
+}
+
+type draftParameters struct {
+	Body       string      `json:"body"`
+	InReplyTo  *uuid.UUID  `json:"in_reply_to"`
+	Visibility string      `json:"visibility"`
+	Mentions   []uuid.UUID `json:"mentions"`
+}
+
+func (cfg *apiConfig) createDraftHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := draftParameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if len(params.Body) > maxDraftBytes {
+		respondWithError(w, http.StatusBadRequest, "Draft is too long", nil)
+		return
+	}
+	audience, err := parseAudience(params.Visibility, params.Mentions)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	dbDraft, err := cfg.dbQueries.CreateDraft(r.Context(), database.CreateDraftParams{
+		UserID:        validatedUserID,
+		Body:          params.Body,
+		ParentChirpID: nullUUID(params.InReplyTo),
+		Visibility:    audience.Visibility,
+		Mentions:      audience.Mentions,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create draft", err)
+		return
+	}
+	draft, err := cfg.draftFromDB(r.Context(), dbDraft)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, draft)
+}
+
+func (cfg *apiConfig) updateDraftHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("draftID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID format", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := draftParameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if len(params.Body) > maxDraftBytes {
+		respondWithError(w, http.StatusBadRequest, "Draft is too long", nil)
+		return
+	}
+	audience, err := parseAudience(params.Visibility, params.Mentions)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	dbDraft, err := cfg.dbQueries.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		Body:          params.Body,
+		ParentChirpID: nullUUID(params.InReplyTo),
+		Visibility:    audience.Visibility,
+		Mentions:      audience.Mentions,
+		ID:            id,
+		UserID:        validatedUserID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Draft not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update draft", err)
+		return
+	}
+	draft, err := cfg.draftFromDB(r.Context(), dbDraft)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, draft)
+}
+
+func (cfg *apiConfig) getDraftsHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorUpdatedAt, cursorID := page.cursorArgs()
+	dbDrafts, err := cfg.dbQueries.GetDrafts(r.Context(), database.GetDraftsParams{
+		UserID:          validatedUserID,
+		CursorUpdatedAt: cursorUpdatedAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve drafts", err)
+		return
+	}
+
+	dbDrafts, next, _ := paginate(dbDrafts, page, func(draft database.Draft) pageCursor {
+		return pageCursor{CreatedAt: draft.UpdatedAt, ID: draft.ID}
+	})
+	drafts := []Draft{}
+	for _, dbDraft := range dbDrafts {
+		draft, err := cfg.draftFromDB(r.Context(), dbDraft)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+			return
+		}
+		drafts = append(drafts, draft)
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, drafts)
+}
+
+func (cfg *apiConfig) deleteDraftHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("draftID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID format", err)
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     id,
+		UserID: validatedUserID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete draft", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Draft not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// publishDraftHandler turns a draft into a chirp. The draft is locked, the
+// chirp created and the draft deleted in one transaction, so a draft is
+// published at most once even if the client retries.
+func (cfg *apiConfig) publishDraftHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("draftID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID format", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	draft, err := qtx.GetDraftForUpdate(r.Context(), database.GetDraftForUpdateParams{
+		ID:     id,
+		UserID: validatedUserID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Draft not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	checked, violations, err := cfg.validateChirp(r.Context(), draft.Body)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+		return
+	}
+	if len(violations) > 0 {
+		respondWithViolations(w, violations)
+		return
+	}
+
+	chirp, err := insertChirp(r.Context(), qtx, database.CreateChirpParams{
+		ID:            uuid.NullUUID{UUID: draft.ID, Valid: true},
+		Body:          checked.Body,
+		UserID:        validatedUserID,
+		ParentChirpID: draft.ParentChirpID,
+		Visibility:    draft.Visibility,
+	}, checked.Flagged, draft.Mentions)
+	if err == nil {
+		err = fanOutChirp(r.Context(), qtx, chirp)
+	}
+	if err == nil {
+		_, err = qtx.DeleteDraft(r.Context(), database.DeleteDraftParams{
+			ID:     draft.ID,
+			UserID: validatedUserID,
+		})
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errParentNotFound):
+			respondWithError(w, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, errReplyToRechirp):
+			respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		return
+	}
+	cfg.queueLinkPreview(chirp)
+
+	respondWithJSON(w, http.StatusCreated, chirpFromDB(chirp))
+}
+
+// draftFromDB runs the draft through chirp validation without enforcing it,
+// so the author can see what needs fixing before publishing.
+func (cfg *apiConfig) draftFromDB(ctx context.Context, draft database.Draft) (Draft, error) {
+	_, violations, err := cfg.validateChirp(ctx, draft.Body)
+	if err != nil {
+		return Draft{}, err
+	}
+	if violations == nil {
+		violations = []validation.Violation{}
+	}
+	return Draft{
+		ID:         draft.ID,
+		CreatedAt:  draft.CreatedAt,
+		UpdatedAt:  draft.UpdatedAt,
+		UserID:     draft.UserID,
+		Body:       draft.Body,
+		InReplyTo:  nullUUIDPtr(draft.ParentChirpID),
+		Visibility: draft.Visibility,
+		Mentions:   draft.Mentions,
+		Warnings:   violations,
+	}, nil
+}
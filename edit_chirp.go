@@ -0,0 +1,191 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const defaultChirpEditWindow = 15 * time.Minute
+
+type ChirpRevision struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Body      string    `json:"body"`
+}
+
+func (cfg *apiConfig) updateChirpHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	checked, violations, err := cfg.validateChirp(r.Context(), params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+		return
+	}
+	if len(violations) > 0 {
+		respondWithViolations(w, violations)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := qtx.ChirpsByIdForUpdate(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if chirp.TombstonedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+	if chirp.UserID != validatedUserID {
+		respondWithNotYourChirp(w, r, qtx, chirp, validatedUserID, "You cannot edit someone elses chirp")
+		return
+	}
+	if chirp.RechirpOfID.Valid {
+		respondWithError(w, http.StatusBadRequest, "Rechirps cannot be edited", nil)
+		return
+	}
+	// Voters answered the question as it was asked. Polls only come with a
+	// new chirp, so checking under the chirp's lock can't race a poll being
+	// added.
+	polls, err := qtx.GetPolls(r.Context(), []uuid.UUID{chirp.ID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if len(polls) > 0 {
+		respondWithError(w, http.StatusConflict, "Chirps with a poll cannot be edited", nil)
+		return
+	}
+	if time.Now().UTC().After(chirp.CreatedAt.Add(cfg.chirpEditWindow)) {
+		respondWithError(w, http.StatusForbidden, "Chirp can no longer be edited", nil)
+		return
+	}
+	if checked.Body == chirp.Body {
+		respondWithJSON(w, http.StatusOK, chirpFromDB(chirp))
+		return
+	}
+
+	_, err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+		ChirpID: chirp.ID,
+		Body:    chirp.Body,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record revision", err)
+		return
+	}
+	updated, err := qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+		Body: checked.Body,
+		ID:   chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	if err := tagChirp(r.Context(), qtx, updated); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't tag chirp", err)
+		return
+	}
+	// The new text is what gets reviewed, so a chirp edited clean leaves
+	// the queue.
+	if len(checked.Flagged) == 0 {
+		err = qtx.UnflagChirp(r.Context(), updated.ID)
+	} else {
+		err = flagChirp(r.Context(), qtx, updated.ID, checked.Flagged)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't flag chirp", err)
+		return
+	}
+	if err := qtx.DeleteLinkPreview(r.Context(), updated.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	cfg.queueLinkPreview(updated)
+
+	respondWithJSON(w, http.StatusOK, chirpFromDB(updated))
+}
+
+func (cfg *apiConfig) getChirpRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	chirp, err := cfg.viewableChirp(r, cfg.dbQueries, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if chirp.TombstonedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+	dbRevisions, err := cfg.dbQueries.GetChirpRevisions(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve revisions", err)
+		return
+	}
+
+	revisions := []ChirpRevision{}
+	for _, dbRevision := range dbRevisions {
+		revisions = append(revisions, ChirpRevision{
+			ID:        dbRevision.ID,
+			CreatedAt: dbRevision.CreatedAt,
+			ChirpID:   dbRevision.ChirpID,
+			Body:      dbRevision.Body,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, revisions)
+}
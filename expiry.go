@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/cygran/chirpy/internal/database"
+)
+
+const (
+	minChirpLifetime          = 5 * time.Minute
+	maxChirpLifetime          = 7 * 24 * time.Hour
+	maxChirpyRedChirpLifetime = 90 * 24 * time.Hour
+	chirpReapInterval         = time.Minute
+	chirpReapBatchSize        = 500
+)
+
+// checkChirpLifetime validates the expires_in seconds sent with a new
+// chirp. Chirpy Red members can keep chirps around for longer.
+func checkChirpLifetime(seconds int64, chirpyRed bool) error {
+	if seconds < int64(minChirpLifetime/time.Second) {
+		return errors.New("Chirps must last at least 5 minutes")
+	}
+	if chirpyRed && seconds > int64(maxChirpyRedChirpLifetime/time.Second) {
+		return errors.New("Chirps can last at most 90 days")
+	}
+	if !chirpyRed && seconds > int64(maxChirpLifetime/time.Second) {
+		return errors.New("Chirps can last at most 7 days without Chirpy Red")
+	}
+	return nil
+}
+
+// chirpExpired reports whether an ephemeral chirp's time is up. Expired
+// chirps are hidden from the moment they expire, before the reaper gets to
+// them.
+func chirpExpired(chirp database.Chirp) bool {
+	return chirp.ExpiresAt.Valid && !chirp.ExpiresAt.Time.After(time.Now().UTC())
+}
+
+// reapExpiredChirpsLoop deletes expired chirps every interval until ctx is
+// done.
+func (cfg *apiConfig) reapExpiredChirpsLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reaped, err := cfg.reapExpiredChirps(ctx)
+		if err != nil {
+			log.Printf("Error reaping expired chirps: %s", err)
+		} else if reaped > 0 {
+			log.Printf("Reaped %d expired chirps", reaped)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reapExpiredChirps permanently removes expired chirps. Likes, hashtags,
+// revisions, mentions, polls, link previews and plain rechirps go with the
+// chirp; its attachments are left for the attachment cleanup loop. Like
+// purged trash, chirps that still have replies are tombstoned instead.
+func (cfg *apiConfig) reapExpiredChirps(ctx context.Context) (int, error) {
+	reaped := 0
+	for {
+		n, err := cfg.reapExpiredChirpsBatch(ctx)
+		reaped += n
+		if err != nil || n < chirpReapBatchSize {
+			return reaped, err
+		}
+	}
+}
+
+func (cfg *apiConfig) reapExpiredChirpsBatch(ctx context.Context) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	expired, err := qtx.GetExpiredChirps(ctx, database.GetExpiredChirpsParams{
+		ExpiredBefore: time.Now().UTC(),
+		Limit:         chirpReapBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, chirp := range expired {
+		if err := destroyChirp(ctx, qtx, chirp.ID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
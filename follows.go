@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type Follow struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func (cfg *apiConfig) followUserHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.setFollow(w, r, true)
+}
+
+func (cfg *apiConfig) unfollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.setFollow(w, r, false)
+}
+
+// setFollow starts or stops the caller following a user, bringing their
+// recent chirps into the caller's timeline or taking them out. Repeating
+// either request is a no-op. The follower and following counts are kept by
+// the database, so they stay right when a user is deleted too.
+func (cfg *apiConfig) setFollow(w http.ResponseWriter, r *http.Request, following bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("userID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format", err)
+		return
+	}
+	if id == validatedUserID {
+		respondWithError(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	if following {
+		if _, err := qtx.GetUserByID(r.Context(), id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "User not found", nil)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+			return
+		}
+		changed, err := qtx.FollowUser(r.Context(), database.FollowUserParams{
+			FollowerID: validatedUserID,
+			FolloweeID: id,
+		})
+		if err == nil && changed > 0 {
+			_, err = backfillTimeline(r.Context(), qtx, validatedUserID, id)
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't follow user", err)
+			return
+		}
+	} else {
+		changed, err := qtx.UnfollowUser(r.Context(), database.UnfollowUserParams{
+			FollowerID: validatedUserID,
+			FolloweeID: id,
+		})
+		if err == nil && changed > 0 {
+			err = qtx.ClearTimeline(r.Context(), database.ClearTimelineParams{
+				UserID:   validatedUserID,
+				AuthorID: uuid.NullUUID{UUID: id, Valid: true},
+			})
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't unfollow user", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update follow", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getFollowersHandler lists who follows a user, most recent first.
+func (cfg *apiConfig) getFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.listFollows(w, r, cfg.dbQueries.GetFollowers, func(follow database.Follow) uuid.UUID {
+		return follow.FollowerID
+	})
+}
+
+// getFollowingHandler lists who a user follows, most recent first.
+func (cfg *apiConfig) getFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.listFollows(w, r, func(ctx context.Context, arg database.GetFollowersParams) ([]database.Follow, error) {
+		return cfg.dbQueries.GetFollowing(ctx, database.GetFollowingParams(arg))
+	}, func(follow database.Follow) uuid.UUID {
+		return follow.FolloweeID
+	})
+}
+
+// listFollows pages through one side of a user's follows. other picks the
+// user at the far end of each follow.
+func (cfg *apiConfig) listFollows(w http.ResponseWriter, r *http.Request, fetch func(context.Context, database.GetFollowersParams) ([]database.Follow, error), other func(database.Follow) uuid.UUID) {
+	idString := r.PathValue("userID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format", err)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if _, err := cfg.dbQueries.GetUserByID(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+
+	cursorCreatedAt, cursorUserID := page.cursorArgs()
+	dbFollows, err := fetch(r.Context(), database.GetFollowersParams{
+		UserID:          id,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorUserID:    cursorUserID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve follows", err)
+		return
+	}
+
+	dbFollows, next, _ := paginate(dbFollows, page, func(follow database.Follow) pageCursor {
+		return pageCursor{CreatedAt: follow.CreatedAt, ID: other(follow)}
+	})
+	follows := []Follow{}
+	for _, dbFollow := range dbFollows {
+		follows = append(follows, Follow{
+			UserID:     other(dbFollow),
+			FollowedAt: dbFollow.CreatedAt,
+		})
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, follows)
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"net/http"
-	"sort"
 
 	"github.com/cygran/chirpy/internal/database"
 	"github.com/google/uuid"
@@ -18,9 +17,20 @@ func (cfg *apiConfig) getChirpsHandler(w http.ResponseWriter, r *http.Request) {
 		sortQuery = "asc"
 	}
 	if sortQuery != "asc" && sortQuery != "desc" {
-		respondWithError(w, http.StatusBadRequest, "Invalid sort query", err)
+		respondWithError(w, http.StatusBadRequest, "Invalid sort query", nil)
 		return
 	}
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	// Paging backwards walks the index in the opposite direction and
+	// paginate flips the rows back into the requested order.
+	descending := (sortQuery == "desc") != page.before
+	cursorCreatedAt, cursorID := page.cursorArgs()
+
+	viewerID, _ := cfg.viewerID(r)
 	idString := r.URL.Query().Get("author_id")
 	if idString != "" {
 		authorId, err := uuid.Parse(idString)
@@ -28,38 +38,63 @@ func (cfg *apiConfig) getChirpsHandler(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusBadRequest, "Invalid author_id", err)
 			return
 		}
-		dbChirps, err = cfg.dbQueries.GetChirpsByUserId(r.Context(), authorId)
+		if descending {
+			dbChirps, err = cfg.dbQueries.GetChirpsByUserIdDesc(r.Context(), database.GetChirpsByUserIdDescParams{
+				UserID:          authorId,
+				ViewerID:        viewerParam(viewerID),
+				CursorCreatedAt: cursorCreatedAt,
+				CursorID:        cursorID,
+				Limit:           page.fetchLimit(),
+			})
+		} else {
+			dbChirps, err = cfg.dbQueries.GetChirpsByUserIdAsc(r.Context(), database.GetChirpsByUserIdAscParams{
+				UserID:          authorId,
+				ViewerID:        viewerParam(viewerID),
+				CursorCreatedAt: cursorCreatedAt,
+				CursorID:        cursorID,
+				Limit:           page.fetchLimit(),
+			})
+		}
 		if err != nil {
 			respondWithError(w, http.StatusInternalServerError, "Couldn't retrive chirps by author", err)
 			return
 		}
 	} else {
-		dbChirps, err = cfg.dbQueries.GetChirps(r.Context())
+		if descending {
+			dbChirps, err = cfg.dbQueries.GetChirpsDesc(r.Context(), database.GetChirpsDescParams{
+				ViewerID:        viewerParam(viewerID),
+				CursorCreatedAt: cursorCreatedAt,
+				CursorID:        cursorID,
+				Limit:           page.fetchLimit(),
+			})
+		} else {
+			dbChirps, err = cfg.dbQueries.GetChirpsAsc(r.Context(), database.GetChirpsAscParams{
+				ViewerID:        viewerParam(viewerID),
+				CursorCreatedAt: cursorCreatedAt,
+				CursorID:        cursorID,
+				Limit:           page.fetchLimit(),
+			})
+		}
 		if err != nil {
 			respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
 			return
 		}
-		if sortQuery == "desc" {
-			sort.Slice(dbChirps, func(i, j int) bool {
-				return dbChirps[i].CreatedAt.After(dbChirps[j].CreatedAt)
-			})
-		} else if sortQuery == "asc" {
-			sort.Slice(dbChirps, func(i, j int) bool {
-				return dbChirps[i].CreatedAt.Before(dbChirps[j].CreatedAt)
-			})
-		}
 	}
 
+	dbChirps, next, prev := paginate(dbChirps, page, chirpCursor)
 	chirps := []Chirp{}
 	for _, dbChirp := range dbChirps {
-		chirps = append(chirps, Chirp{
-			ID:        dbChirp.ID,
-			CreatedAt: dbChirp.CreatedAt,
-			UpdatedAt: dbChirp.UpdatedAt,
-			UserID:    dbChirp.UserID,
-			Body:      dbChirp.Body,
-		})
+		chirps = append(chirps, chirpFromDB(dbChirp))
 	}
+	if err := cfg.decorateChirps(r, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	setPageLinks(w, r, next, prev)
+	respondWithCacheableJSON(w, r, chirps, latestUpdate(chirps))
+}
 
-	respondWithJSON(w, http.StatusOK, chirps)
+func chirpCursor(chirp database.Chirp) pageCursor {
+	return pageCursor{CreatedAt: chirp.CreatedAt, ID: chirp.ID}
 }
@@ -17,18 +17,19 @@ func (cfg *apiConfig) getChirpsByIdHandler(w http.ResponseWriter, r *http.Reques
 		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
 		return
 	}
-	chirp, err := cfg.dbQueries.ChirpsById(r.Context(), id)
+	chirp, err := cfg.viewableChirp(r, cfg.dbQueries, id)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
 		return
 	}
-	respondWithJSON(w, http.StatusOK, response{
-		Chirp: Chirp{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserID:    chirp.UserID,
-		},
-	})
+	resp := response{
+		Chirp: chirpFromDB(chirp),
+	}
+	chirps := []Chirp{resp.Chirp}
+	if err := cfg.decorateChirps(r, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+	resp.Chirp = chirps[0]
+	respondWithCacheableJSON(w, r, resp, latestUpdate(chirps))
 }
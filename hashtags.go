@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/hashtags"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTrendingWindow = time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+	defaultTrendingLimit  = 10
+	maxTrendingLimit      = 50
+	hashtagBackfillBatch  = 500
+)
+
+type TrendingHashtag struct {
+	Tag          string `json:"tag"`
+	RecentUses   int32  `json:"recent_uses"`
+	PreviousUses int32  `json:"previous_uses"`
+	Velocity     int32  `json:"velocity"`
+}
+
+// tagChirp records the hashtags in a chirp's body. Tags the chirp already had
+// are cleared first, so it is also used after an edit.
+func tagChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	if err := q.ClearChirpHashtags(ctx, chirp.ID); err != nil {
+		return err
+	}
+	// Hashtags are for finding chirps, so only public ones are tagged.
+	if chirp.Visibility != visibilityPublic {
+		return nil
+	}
+	for _, tag := range hashtags.Extract(chirp.Body) {
+		hashtag, err := q.UpsertHashtag(ctx, tag)
+		if err != nil {
+			return err
+		}
+		err = q.AddChirpHashtag(ctx, database.AddChirpHashtagParams{
+			ChirpID:   chirp.ID,
+			HashtagID: hashtag.ID,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) getHashtagChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	tag := hashtags.Normalize(r.PathValue("tag"))
+	if tag == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid hashtag", nil)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := page.cursorArgs()
+	dbChirps, err := cfg.dbQueries.GetChirpsByHashtag(r.Context(), database.GetChirpsByHashtagParams{
+		Tag:             tag,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+		return
+	}
+
+	dbChirps, next, _ := paginate(dbChirps, page, chirpCursor)
+	chirps := []Chirp{}
+	for _, dbChirp := range dbChirps {
+		chirps = append(chirps, chirpFromDB(dbChirp))
+	}
+	if err := cfg.decorateChirps(r, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, chirps)
+}
+
+// getTrendingHashtagsHandler ranks tags by how much more they were used in
+// the last window than in the window before it, so a tag that is suddenly
+// taking off beats one that is merely always busy. Only public chirps that
+// are still up and whose authors are in good standing count.
+func (cfg *apiConfig) getTrendingHashtagsHandler(w http.ResponseWriter, r *http.Request) {
+	window := defaultTrendingWindow
+	if windowString := r.URL.Query().Get("window"); windowString != "" {
+		parsed, err := time.ParseDuration(windowString)
+		if err != nil || parsed <= 0 || parsed > maxTrendingWindow {
+			respondWithError(w, http.StatusBadRequest, "Invalid window", err)
+			return
+		}
+		window = parsed
+	}
+	limit := defaultTrendingLimit
+	if limitString := r.URL.Query().Get("limit"); limitString != "" {
+		parsed, err := strconv.Atoi(limitString)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+			return
+		}
+		limit = min(parsed, maxTrendingLimit)
+	}
+
+	now := time.Now().UTC()
+	rows, err := cfg.dbQueries.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		WindowStart:         now.Add(-window),
+		PreviousWindowStart: now.Add(-2 * window),
+		Limit:               int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve trending hashtags", err)
+		return
+	}
+
+	trending := []TrendingHashtag{}
+	for _, row := range rows {
+		trending = append(trending, TrendingHashtag{
+			Tag:          row.Tag,
+			RecentUses:   row.RecentUses,
+			PreviousUses: row.PreviousUses,
+			Velocity:     row.Velocity,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, trending)
+}
+
+// backfillHashtagsHandler tags every existing chirp. It is safe to re-run:
+// each chirp's tags are rebuilt from its current body.
+func (cfg *apiConfig) backfillHashtagsHandler(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		ChirpsProcessed int `json:"chirps_processed"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	processed := 0
+	params := database.GetChirpsAscParams{Limit: hashtagBackfillBatch}
+	for {
+		batch, err := cfg.dbQueries.GetChirpsAsc(r.Context(), params)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+			return
+		}
+		for _, chirp := range batch {
+			if err := tagChirp(r.Context(), cfg.dbQueries, chirp); err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Couldn't tag chirp", err)
+				return
+			}
+		}
+		processed += len(batch)
+		if len(batch) < hashtagBackfillBatch {
+			break
+		}
+		last := batch[len(batch)-1]
+		params.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: last.ID, Valid: true}
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		ChirpsProcessed: processed,
+	})
+}
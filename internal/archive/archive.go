@@ -0,0 +1,247 @@
+// Package archive reads and writes personal chirp archives: a zip holding a
+// manifest, the user's profile and their chirps as newline-delimited JSON.
+// It also reads Twitter's tweets.js export so people can bring their
+// history with them.
+package archive
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Format identifies Chirpy archives in their manifest.
+	Format = "chirpy-archive"
+	// Version is the archive layout written by Writer.
+	Version = 1
+
+	manifestFile = "manifest.json"
+	profileFile  = "profile.json"
+	chirpsFile   = "chirps.ndjson"
+
+	// MaxChirps caps how many chirps Read returns from one archive.
+	MaxChirps = 50000
+	// maxFileBytes caps the uncompressed size of any file read from an
+	// archive, so a small zip can't expand into something huge.
+	maxFileBytes = 256 << 20
+	maxLineBytes = 1 << 20
+)
+
+var (
+	// ErrUnrecognized is returned by Read for data that is neither a Chirpy
+	// archive nor a Twitter export.
+	ErrUnrecognized = errors.New("archive: unrecognized format")
+	// ErrTooManyChirps is returned by Read when an archive holds more than
+	// MaxChirps chirps.
+	ErrTooManyChirps = errors.New("archive: too many chirps")
+)
+
+type Manifest struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Chirps     int       `json:"chirps"`
+}
+
+type Profile struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	ChirpyRed bool      `json:"is_chirpy_red"`
+}
+
+// Chirp is one chirp as archived. InReplyTo and QuotedChirpID refer to
+// chirps by their ID on the instance the archive came from.
+type Chirp struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Body          string     `json:"body"`
+	Visibility    string     `json:"visibility,omitempty"`
+	InReplyTo     *uuid.UUID `json:"in_reply_to,omitempty"`
+	QuotedChirpID *uuid.UUID `json:"quoted_chirp_id,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// Archive is the contents of an archive. Profile is nil for Twitter
+// exports.
+type Archive struct {
+	Manifest Manifest
+	Profile  *Profile
+	Chirps   []Chirp
+}
+
+// Writer streams an archive. Call WriteChirp for each chirp, oldest first,
+// then Close to write the manifest and finish the zip.
+type Writer struct {
+	zw     *zip.Writer
+	chirps *json.Encoder
+	count  int
+}
+
+// NewWriter starts an archive for profile on w.
+func NewWriter(w io.Writer, profile Profile) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	if err := writeJSON(zw, profileFile, profile); err != nil {
+		return nil, err
+	}
+	f, err := zw.Create(chirpsFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{zw: zw, chirps: json.NewEncoder(f)}, nil
+}
+
+// WriteChirp adds a chirp to the archive.
+func (w *Writer) WriteChirp(chirp Chirp) error {
+	if err := w.chirps.Encode(chirp); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Close writes the manifest and the zip's central directory. It does not
+// close the underlying writer.
+func (w *Writer) Close(exportedAt time.Time) error {
+	err := writeJSON(w.zw, manifestFile, Manifest{
+		Format:     Format,
+		Version:    Version,
+		ExportedAt: exportedAt.UTC(),
+		Chirps:     w.count,
+	})
+	if err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(f).Encode(v)
+}
+
+// Read parses a Chirpy archive, a Twitter archive zip, or a bare tweets.js
+// file.
+func Read(data []byte) (Archive, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		chirps, err := ParseTweets(bytes.NewReader(data))
+		if err != nil {
+			return Archive{}, err
+		}
+		return Archive{Manifest: Manifest{Chirps: len(chirps)}, Chirps: chirps}, nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Archive{}, fmt.Errorf("archive: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	if f, ok := files[manifestFile]; ok {
+		return readChirpyArchive(f, files)
+	}
+	for _, name := range []string{"data/tweets.js", "tweets.js", "data/tweet.js", "tweet.js"} {
+		if f, ok := files[name]; ok {
+			rc, err := open(f)
+			if err != nil {
+				return Archive{}, err
+			}
+			defer rc.Close()
+			chirps, err := ParseTweets(rc)
+			if err != nil {
+				return Archive{}, err
+			}
+			return Archive{Manifest: Manifest{Chirps: len(chirps)}, Chirps: chirps}, nil
+		}
+	}
+	return Archive{}, ErrUnrecognized
+}
+
+func readChirpyArchive(manifestFile *zip.File, files map[string]*zip.File) (Archive, error) {
+	archive := Archive{}
+	if err := readJSON(manifestFile, &archive.Manifest); err != nil {
+		return Archive{}, err
+	}
+	if archive.Manifest.Format != Format {
+		return Archive{}, ErrUnrecognized
+	}
+	if archive.Manifest.Version > Version {
+		return Archive{}, fmt.Errorf("archive: unsupported version %d", archive.Manifest.Version)
+	}
+	if f, ok := files[profileFile]; ok {
+		archive.Profile = &Profile{}
+		if err := readJSON(f, archive.Profile); err != nil {
+			return Archive{}, err
+		}
+	}
+
+	f, ok := files[chirpsFile]
+	if !ok {
+		return Archive{}, fmt.Errorf("archive: missing %s", chirpsFile)
+	}
+	rc, err := open(f)
+	if err != nil {
+		return Archive{}, err
+	}
+	defer rc.Close()
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if len(archive.Chirps) == MaxChirps {
+			return Archive{}, ErrTooManyChirps
+		}
+		chirp := Chirp{}
+		if err := json.Unmarshal(scanner.Bytes(), &chirp); err != nil {
+			return Archive{}, fmt.Errorf("archive: %s line %d: %w", chirpsFile, line, err)
+		}
+		archive.Chirps = append(archive.Chirps, chirp)
+	}
+	if err := scanner.Err(); err != nil {
+		return Archive{}, fmt.Errorf("archive: %s: %w", chirpsFile, err)
+	}
+	return archive, nil
+}
+
+func readJSON(f *zip.File, v any) error {
+	rc, err := open(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("archive: %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func open(f *zip.File) (io.ReadCloser, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("archive: %s: %w", f.Name, err)
+	}
+	return limitedReadCloser{Reader: io.LimitReader(rc, maxFileBytes), Closer: rc}, nil
+}
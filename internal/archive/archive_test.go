@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	parent := uuid.New()
+	profile := Profile{ID: uuid.New(), Email: "user@example.com", CreatedAt: now.Add(-time.Hour)}
+	chirps := []Chirp{
+		{ID: parent, CreatedAt: now, UpdatedAt: now, Body: "first", Visibility: "public"},
+		{ID: uuid.New(), CreatedAt: now.Add(time.Minute), UpdatedAt: now.Add(time.Minute), Body: "second", Visibility: "private", InReplyTo: &parent},
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, profile)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, chirp := range chirps {
+		if err := w.WriteChirp(chirp); err != nil {
+			t.Fatalf("WriteChirp: %v", err)
+		}
+	}
+	if err := w.Close(now); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got, err := Read(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Manifest.Format != Format || got.Manifest.Version != Version || got.Manifest.Chirps != 2 {
+		t.Errorf("manifest = %+v", got.Manifest)
+	}
+	if got.Profile == nil || *got.Profile != profile {
+		t.Errorf("profile = %+v, want %+v", got.Profile, profile)
+	}
+	if len(got.Chirps) != 2 {
+		t.Fatalf("got %d chirps, want 2", len(got.Chirps))
+	}
+	if got.Chirps[1].Body != "second" || got.Chirps[1].InReplyTo == nil || *got.Chirps[1].InReplyTo != parent {
+		t.Errorf("second chirp = %+v", got.Chirps[1])
+	}
+}
+
+const tweetsJS = `window.YTD.tweets.part0 = [
+  {"tweet": {"id_str": "2", "created_at": "Thu Oct 11 09:00:00 +0000 2018",
+    "full_text": "a reply &amp; more", "in_reply_to_status_id_str": "1"}},
+  {"tweet": {"id_str": "1", "created_at": "Wed Oct 10 20:19:24 +0000 2018",
+    "full_text": "hello &lt;world&gt;"}},
+  {"tweet": {"id_str": "3", "created_at": "Fri Oct 12 09:00:00 +0000 2018",
+    "full_text": "RT @someone: not mine"}},
+  {"tweet": {"id_str": "4", "created_at": "Sat Oct 13 09:00:00 +0000 2018",
+    "full_text": "replying elsewhere", "in_reply_to_status_id_str": "999"}}
+]`
+
+func TestParseTweets(t *testing.T) {
+	chirps, err := ParseTweets(strings.NewReader(tweetsJS))
+	if err != nil {
+		t.Fatalf("ParseTweets: %v", err)
+	}
+	if len(chirps) != 3 {
+		t.Fatalf("got %d chirps, want 3", len(chirps))
+	}
+	first, reply, other := chirps[0], chirps[1], chirps[2]
+	if first.Body != "hello <world>" || first.ID != tweetChirpID("1") {
+		t.Errorf("first = %+v", first)
+	}
+	if !first.CreatedAt.Equal(time.Date(2018, 10, 10, 20, 19, 24, 0, time.UTC)) {
+		t.Errorf("first.CreatedAt = %v", first.CreatedAt)
+	}
+	if reply.Body != "a reply & more" || reply.InReplyTo == nil || *reply.InReplyTo != first.ID {
+		t.Errorf("reply = %+v", reply)
+	}
+	if other.InReplyTo != nil {
+		t.Errorf("reply to a tweet outside the export kept its parent: %+v", other)
+	}
+}
+
+func TestReadTwitterZip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	f, _ := zw.Create("data/tweets.js")
+	f.Write([]byte(tweetsJS))
+	zw.Close()
+
+	got, err := Read(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Profile != nil || len(got.Chirps) != 3 {
+		t.Errorf("Read() = %+v", got)
+	}
+}
+
+func TestReadUnrecognized(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	zw.Create("something.txt")
+	zw.Close()
+
+	for name, data := range map[string][]byte{
+		"zip":  buf.Bytes(),
+		"text": []byte("not an archive"),
+		"js":   []byte("var x = [1, 2]"),
+	} {
+		if _, err := Read(data); !errors.Is(err, ErrUnrecognized) {
+			t.Errorf("%s: got %v, want ErrUnrecognized", name, err)
+		}
+	}
+}
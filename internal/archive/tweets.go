@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tweetNamespace derives stable chirp IDs from tweet IDs, so importing the
+// same export twice finds the chirps it already created.
+var tweetNamespace = uuid.MustParse("4f0d5a56-3c1e-4d8e-9f63-2b7f5a0c9e11")
+
+const tweetTimeLayout = "Mon Jan 02 15:04:05 -0700 2006"
+
+type tweetEntry struct {
+	Tweet tweet `json:"tweet"`
+}
+
+type tweet struct {
+	ID          string `json:"id_str"`
+	CreatedAt   string `json:"created_at"`
+	FullText    string `json:"full_text"`
+	InReplyToID string `json:"in_reply_to_status_id_str"`
+	Retweeted   bool   `json:"retweeted"`
+}
+
+// ParseTweets reads the tweets.js file from a Twitter archive. The file is
+// a JavaScript assignment wrapped around a JSON array, so everything before
+// the array is skipped. Retweets are left out, and replies keep their parent
+// only when it's in the same export. Chirps come back oldest first.
+func ParseTweets(r io.Reader) ([]Chirp, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.ReadBytes('[')
+	if err != nil {
+		return nil, ErrUnrecognized
+	}
+	assignment := bytes.TrimSpace(bytes.TrimSuffix(prefix, []byte("[")))
+	if len(assignment) > 0 && !bytes.HasPrefix(assignment, []byte("window.YTD.")) {
+		return nil, ErrUnrecognized
+	}
+	entries := []tweetEntry{}
+	if err := json.NewDecoder(io.MultiReader(strings.NewReader("["), br)).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("archive: tweets.js: %w", err)
+	}
+	if len(entries) > MaxChirps {
+		return nil, ErrTooManyChirps
+	}
+
+	ids := make(map[string]uuid.UUID, len(entries))
+	for _, entry := range entries {
+		ids[entry.Tweet.ID] = tweetChirpID(entry.Tweet.ID)
+	}
+	chirps := []Chirp{}
+	for _, entry := range entries {
+		t := entry.Tweet
+		if t.Retweeted || strings.HasPrefix(t.FullText, "RT @") {
+			continue
+		}
+		createdAt, err := time.Parse(tweetTimeLayout, t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("archive: tweet %s: %w", t.ID, err)
+		}
+		chirp := Chirp{
+			ID:        ids[t.ID],
+			CreatedAt: createdAt.UTC(),
+			UpdatedAt: createdAt.UTC(),
+			Body:      html.UnescapeString(t.FullText),
+		}
+		if parent, ok := ids[t.InReplyToID]; ok {
+			chirp.InReplyTo = &parent
+		}
+		chirps = append(chirps, chirp)
+	}
+	sort.SliceStable(chirps, func(i, j int) bool {
+		return chirps[i].CreatedAt.Before(chirps[j].CreatedAt)
+	})
+	return chirps, nil
+}
+
+func tweetChirpID(tweetID string) uuid.UUID {
+	return uuid.NewSHA1(tweetNamespace, []byte(tweetID))
+}
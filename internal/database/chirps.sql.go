@@ -7,29 +7,52 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_chirp_id, root_chirp_id, quoted_chirp_id, visibility, expires_at)
 VALUES (
-    gen_random_uuid(),
-    NOW(),
-    NOW(),
+    COALESCE($8::uuid, gen_random_uuid()),
+    COALESCE($9::timestamp, NOW()),
+    COALESCE($9::timestamp, NOW()),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body          string
+	UserID        uuid.UUID
+	ParentChirpID uuid.NullUUID
+	RootChirpID   uuid.NullUUID
+	QuotedChirpID uuid.NullUUID
+	Visibility    string
+	ExpiresAt     sql.NullTime
+	ID            uuid.NullUUID
+	CreatedAt     sql.NullTime
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ParentChirpID,
+		arg.RootChirpID,
+		arg.QuotedChirpID,
+		arg.Visibility,
+		arg.ExpiresAt,
+		arg.ID,
+		arg.CreatedAt,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +60,21 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.EditedAt,
+		&i.ParentChirpID,
+		&i.RootChirpID,
+		&i.ReplyCount,
+		&i.TombstonedAt,
+		&i.LikeCount,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.ExpiresAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
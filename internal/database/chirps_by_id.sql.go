@@ -9,11 +9,13 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const chirpsById = `-- name: ChirpsById :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
 `
 
 func (q *Queries) ChirpsById(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -25,6 +27,197 @@ func (q *Queries) ChirpsById(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.EditedAt,
+		&i.ParentChirpID,
+		&i.RootChirpID,
+		&i.ReplyCount,
+		&i.TombstonedAt,
+		&i.LikeCount,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const chirpsByIdForUpdate = `-- name: ChirpsByIdForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+FOR UPDATE
+`
+
+func (q *Queries) ChirpsByIdForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, chirpsByIdForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.EditedAt,
+		&i.ParentChirpID,
+		&i.RootChirpID,
+		&i.ReplyCount,
+		&i.TombstonedAt,
+		&i.LikeCount,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const chirpsByIds = `-- name: ChirpsByIds :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND chirp_visible_to(id, user_id, visibility, $2::uuid)
+`
+
+type ChirpsByIdsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) ChirpsByIds(ctx context.Context, arg ChirpsByIdsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, chirpsByIds, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.EditedAt,
+			&i.ParentChirpID,
+			&i.RootChirpID,
+			&i.ReplyCount,
+			&i.TombstonedAt,
+			&i.LikeCount,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.ExpiresAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const chirpVisibleTo = `-- name: ChirpVisibleTo :one
+SELECT chirp_visible_to(id, user_id, visibility, $1::uuid) AS visible
+FROM chirps
+WHERE id = $2
+`
+
+type ChirpVisibleToParams struct {
+	ViewerID uuid.NullUUID
+	ID       uuid.UUID
+}
+
+func (q *Queries) ChirpVisibleTo(ctx context.Context, arg ChirpVisibleToParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, chirpVisibleTo, arg.ViewerID, arg.ID)
+	var visible bool
+	err := row.Scan(&visible)
+	return visible, err
+}
+
+const chirpsByIdIncludingDeleted = `-- name: ChirpsByIdIncludingDeleted :one
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE id = $1
+`
+
+func (q *Queries) ChirpsByIdIncludingDeleted(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, chirpsByIdIncludingDeleted, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.EditedAt,
+		&i.ParentChirpID,
+		&i.RootChirpID,
+		&i.ReplyCount,
+		&i.TombstonedAt,
+		&i.LikeCount,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const chirpsByIdIncludingDeletedForUpdate = `-- name: ChirpsByIdIncludingDeletedForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) ChirpsByIdIncludingDeletedForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, chirpsByIdIncludingDeletedForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.EditedAt,
+		&i.ParentChirpID,
+		&i.RootChirpID,
+		&i.ReplyCount,
+		&i.TombstonedAt,
+		&i.LikeCount,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.ExpiresAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
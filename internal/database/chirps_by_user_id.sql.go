@@ -7,18 +7,109 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
-const getChirpsByUserId = `-- name: GetChirpsByUserId :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+const getChirpsByUserIdAsc = `-- name: GetChirpsByUserIdAsc :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
 WHERE user_id = $1
-ORDER BY created_at ASC
+    AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND chirp_visible_to(id, user_id, visibility, $2::uuid)
+    AND ($3::timestamp IS NULL
+        OR (created_at, id) > ($3::timestamp, $4::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $5
 `
 
-func (q *Queries) GetChirpsByUserId(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByUserId, userID)
+type GetChirpsByUserIdAscParams struct {
+	UserID          uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetChirpsByUserIdAsc(ctx context.Context, arg GetChirpsByUserIdAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByUserIdAsc,
+		arg.UserID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.EditedAt,
+			&i.ParentChirpID,
+			&i.RootChirpID,
+			&i.ReplyCount,
+			&i.TombstonedAt,
+			&i.LikeCount,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.ExpiresAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByUserIdDesc = `-- name: GetChirpsByUserIdDesc :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE user_id = $1
+    AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND chirp_visible_to(id, user_id, visibility, $2::uuid)
+    AND ($3::timestamp IS NULL
+        OR (created_at, id) < ($3::timestamp, $4::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type GetChirpsByUserIdDescParams struct {
+	UserID          uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetChirpsByUserIdDesc(ctx context.Context, arg GetChirpsByUserIdDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByUserIdDesc,
+		arg.UserID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +123,21 @@ func (q *Queries) GetChirpsByUserId(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.EditedAt,
+			&i.ParentChirpID,
+			&i.RootChirpID,
+			&i.ReplyCount,
+			&i.TombstonedAt,
+			&i.LikeCount,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.ExpiresAt,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -7,15 +7,113 @@ package database
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+const getChirpsAsc = `-- name: GetChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE deleted_at IS NULL
+    AND visibility = 'public'
+    AND hidden_at IS NULL
+    AND (user_id = $1::uuid OR NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id AND users.account_state = 'shadow_banned'
+    ))
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND ($2::timestamp IS NULL
+        OR (created_at, id) > ($2::timestamp, $3::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type GetChirpsAscParams struct {
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetChirpsAsc(ctx context.Context, arg GetChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsAsc,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.EditedAt,
+			&i.ParentChirpID,
+			&i.RootChirpID,
+			&i.ReplyCount,
+			&i.TombstonedAt,
+			&i.LikeCount,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.ExpiresAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_chirp_id, root_chirp_id, reply_count, tombstoned_at, like_count, rechirp_of_id, quoted_chirp_id, rechirp_count, quote_count, search_vector, deleted_at, visibility, expires_at, hidden_at FROM chirps
+WHERE deleted_at IS NULL
+    AND visibility = 'public'
+    AND hidden_at IS NULL
+    AND (user_id = $1::uuid OR NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id AND users.account_state = 'shadow_banned'
+    ))
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND ($2::timestamp IS NULL
+        OR (created_at, id) < ($2::timestamp, $3::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $4
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpsDescParams struct {
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +127,21 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.EditedAt,
+			&i.ParentChirpID,
+			&i.RootChirpID,
+			&i.ReplyCount,
+			&i.TombstonedAt,
+			&i.LikeCount,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.ExpiresAt,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -11,12 +11,155 @@ import (
 	"github.com/google/uuid"
 )
 
+type AccountStateChange struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UserID         uuid.UUID
+	Moderator      string
+	AccountState   string
+	SuspendedUntil sql.NullTime
+	Reason         string
+}
+
+type Attachment struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UserID               uuid.UUID
+	ChirpID              uuid.NullUUID
+	Position             int32
+	ContentType          string
+	SizeBytes            int32
+	Width                int32
+	Height               int32
+	StorageKey           string
+	ThumbnailKey         string
+	ThumbnailContentType string
+	AltText              string
+	AttachedAt           sql.NullTime
+}
+
 type Chirp struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.UUID
+	EditedAt      sql.NullTime
+	ParentChirpID uuid.NullUUID
+	RootChirpID   uuid.NullUUID
+	ReplyCount    int32
+	TombstonedAt  sql.NullTime
+	LikeCount     int32
+	RechirpOfID   uuid.NullUUID
+	QuotedChirpID uuid.NullUUID
+	RechirpCount  int32
+	QuoteCount    int32
+	SearchVector  interface{}
+	DeletedAt     sql.NullTime
+	Visibility    string
+	ExpiresAt     sql.NullTime
+	HiddenAt      sql.NullTime
+}
+
+type ChirpEvent struct {
+	ID        int64
+	CreatedAt time.Time
+	Kind      string
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpMention struct {
+	ChirpID uuid.UUID
+	UserID  uuid.UUID
+}
+
+type ChirpRevision struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
-	UpdatedAt time.Time
+	ChirpID   uuid.UUID
 	Body      string
+}
+
+type Draft struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uuid.UUID
+	Body          string
+	ParentChirpID uuid.NullUUID
+	Visibility    string
+	Mentions      []uuid.UUID
+}
+
+type FlaggedChirp struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+	Words     []string
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Tag       string
+}
+
+type LinkPreview struct {
+	ChirpID     uuid.UUID
+	CreatedAt   time.Time
+	Url         string
+	Title       string
+	Description string
+	ImageUrl    string
+}
+
+type ModerationAction struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ReportID  uuid.UUID
+	Moderator string
+	Action    string
+	ChirpID   uuid.NullUUID
 	UserID    uuid.UUID
+	Note      string
+}
+
+type Poll struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type PollOption struct {
+	ChirpID   uuid.UUID
+	Position  int32
+	Text      string
+	VoteCount int32
+}
+
+type PollVote struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	Position  int32
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,6 +171,45 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type Report struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ChirpID     uuid.NullUUID
+	ChirpUserID uuid.UUID
+	ChirpBody   string
+	ReporterID  uuid.UUID
+	Reason      string
+	Details     string
+	Status      string
+	ClaimedBy   sql.NullString
+	ClaimedAt   sql.NullTime
+	Resolution  sql.NullString
+	ResolvedBy  sql.NullString
+	ResolvedAt  sql.NullTime
+}
+
+type ScheduledChirp struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uuid.UUID
+	Body          string
+	ParentChirpID uuid.NullUUID
+	FlaggedWords  []string
+	PublishAt     time.Time
+	PublishError  sql.NullString
+	Visibility    string
+	Mentions      []uuid.UUID
+}
+
+type TimelineEntry struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	AuthorID  uuid.UUID
+	CreatedAt time.Time
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
@@ -35,4 +217,23 @@ type User struct {
 	Email          string
 	HashedPassword string
 	IsChirpyRed    bool
+	AccountState   string
+	SuspendedUntil sql.NullTime
+	StateReason    string
+	StateChangedAt sql.NullTime
+	FollowerCount  int32
+	FollowingCount int32
+	Handle         sql.NullString
+	DisplayName    string
+	Bio            string
+	Website        string
+	AvatarUrl      string
+}
+
+type WordFilter struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Word      string
+	Action    string
 }
@@ -16,7 +16,7 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET email = $1, hashed_password = $2, updated_at = Now()
 WHERE id = $3
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, account_state, suspended_until, state_reason, state_changed_at, follower_count, following_count, handle, display_name, bio, website, avatar_url
 `
 
 type UpdateUserParams struct {
@@ -35,6 +35,17 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.AccountState,
+		&i.SuspendedUntil,
+		&i.StateReason,
+		&i.StateChangedAt,
+		&i.FollowerCount,
+		&i.FollowingCount,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
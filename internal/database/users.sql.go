@@ -7,6 +7,8 @@ package database
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -18,7 +20,7 @@ VALUES (
     $1,
     $2
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, account_state, suspended_until, state_reason, state_changed_at, follower_count, following_count, handle, display_name, bio, website, avatar_url
 `
 
 type CreateUserParams struct {
@@ -36,6 +38,47 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.AccountState,
+		&i.SuspendedUntil,
+		&i.StateReason,
+		&i.StateChangedAt,
+		&i.FollowerCount,
+		&i.FollowingCount,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, account_state, suspended_until, state_reason, state_changed_at, follower_count, following_count, handle, display_name, bio, website, avatar_url FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.AccountState,
+		&i.SuspendedUntil,
+		&i.StateReason,
+		&i.StateChangedAt,
+		&i.FollowerCount,
+		&i.FollowingCount,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
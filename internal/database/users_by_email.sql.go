@@ -10,7 +10,7 @@ import (
 )
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, account_state, suspended_until, state_reason, state_changed_at, follower_count, following_count, handle, display_name, bio, website, avatar_url FROM users
 WHERE email = $1
 `
 
@@ -24,6 +24,17 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.AccountState,
+		&i.SuspendedUntil,
+		&i.StateReason,
+		&i.StateChangedAt,
+		&i.FollowerCount,
+		&i.FollowingCount,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
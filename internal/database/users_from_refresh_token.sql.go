@@ -7,13 +7,14 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
-SELECT id, email, created_at, updated_at
+SELECT id, email, created_at, updated_at, account_state, suspended_until
 FROM users
 WHERE id = (
     SELECT user_id FROM refresh_tokens WHERE token = $1
@@ -21,10 +22,12 @@ WHERE id = (
 `
 
 type GetUserFromRefreshTokenRow struct {
-	ID        uuid.UUID
-	Email     string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID             uuid.UUID
+	Email          string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	AccountState   string
+	SuspendedUntil sql.NullTime
 }
 
 func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (GetUserFromRefreshTokenRow, error) {
@@ -35,6 +38,8 @@ func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (Ge
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AccountState,
+		&i.SuspendedUntil,
 	)
 	return i, err
 }
@@ -0,0 +1,55 @@
+package hashtags
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength is the longest tag, in runes, that Extract will return.
+const MaxLength = 64
+
+// Extract returns the normalized hashtags in body, de-duplicated and in the
+// order they first appear. A tag starts with '#' at the beginning of the body
+// or after a character that cannot be part of a word, runs over letters,
+// digits and underscores, and must contain at least one letter.
+func Extract(body string) []string {
+	tags := []string{}
+	seen := map[string]struct{}{}
+	runes := []rune(body)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' {
+			continue
+		}
+		if i > 0 && (isTagRune(runes[i-1]) || runes[i-1] == '#') {
+			continue
+		}
+		end := i + 1
+		hasLetter := false
+		for end < len(runes) && isTagRune(runes[end]) {
+			if unicode.IsLetter(runes[end]) {
+				hasLetter = true
+			}
+			end++
+		}
+		length := end - i - 1
+		if hasLetter && length <= MaxLength {
+			tag := Normalize(string(runes[i+1 : end]))
+			if _, ok := seen[tag]; !ok {
+				seen[tag] = struct{}{}
+				tags = append(tags, tag)
+			}
+		}
+		i = end - 1
+	}
+	return tags
+}
+
+// Normalize lower-cases a tag and strips a leading '#', so that "#Go" and
+// "go" refer to the same tag.
+func Normalize(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
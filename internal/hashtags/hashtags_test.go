@@ -0,0 +1,44 @@
+package hashtags
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"No Tags", "just a normal chirp", []string{}},
+		{"Single Tag", "learning #Go today", []string{"go"}},
+		{"Trailing Punctuation", "shipping it #release!", []string{"release"}},
+		{"Duplicates Case Insensitive", "#Go #go #GO", []string{"go"}},
+		{"Order Preserved", "#beta then #alpha", []string{"beta", "alpha"}},
+		{"Unicode Letters", "café time #Café", []string{"café"}},
+		{"Numbers Only Ignored", "issue #123", []string{}},
+		{"Mixed Letters And Numbers", "#go123 #2fast", []string{"go123", "2fast"}},
+		{"Inside Word Ignored", "email me at a#b", []string{}},
+		{"Double Hash Ignored", "##nope", []string{}},
+		{"Bare Hash Ignored", "# and #", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(tt.body)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Extract(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractSkipsOverlongTags(t *testing.T) {
+	long := "#"
+	for range MaxLength + 1 {
+		long += "a"
+	}
+	if got := Extract(long); len(got) != 0 {
+		t.Errorf("expected overlong tag to be skipped, got %v", got)
+	}
+}
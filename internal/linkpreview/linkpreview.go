@@ -0,0 +1,210 @@
+// Package linkpreview fetches pages linked from chirps and pulls out the
+// OpenGraph or Twitter card metadata used to render a preview.
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// DefaultMaxBytes is how much of a page is read looking for metadata.
+	// The tags we want live in the head, so this rarely cuts anything off.
+	DefaultMaxBytes = 512 << 10
+
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxURLLength         = 2048
+)
+
+var (
+	ErrNotHTML   = errors.New("linkpreview: response is not HTML")
+	ErrNoPreview = errors.New("linkpreview: page has no preview metadata")
+)
+
+// Preview is what a page says about itself.
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+}
+
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// FirstURL returns the first http or https link in body, without any
+// punctuation that ends the sentence around it.
+func FirstURL(body string) (string, bool) {
+	for _, match := range urlPattern.FindAllString(body, -1) {
+		match = strings.TrimRight(match, `.,;:!?'")]}>`)
+		if len(match) > maxURLLength {
+			continue
+		}
+		if u, err := url.Parse(match); err == nil && u.Host != "" {
+			return match, true
+		}
+	}
+	return "", false
+}
+
+// Fetcher downloads pages and parses their previews. Client should come from
+// NewClient outside of tests so fetches can't reach internal addresses.
+type Fetcher struct {
+	Client   *http.Client
+	MaxBytes int64
+}
+
+// Fetch retrieves rawURL and parses its preview. Preview.URL is the page's
+// address after redirects.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Preview, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Preview{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Preview{}, fmt.Errorf("linkpreview: unsupported scheme %q", u.Scheme)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Preview{}, err
+	}
+	req.Header.Set("User-Agent", "Chirpy-LinkPreview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return Preview{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Preview{}, fmt.Errorf("linkpreview: %s returned %s", u.Host, resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return Preview{}, ErrNotHTML
+	}
+
+	maxBytes := f.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxBytes), contentType)
+	if err != nil {
+		return Preview{}, err
+	}
+	preview := Parse(body, resp.Request.URL)
+	if preview.Title == "" && preview.Description == "" {
+		return Preview{}, ErrNoPreview
+	}
+	return preview, nil
+}
+
+// Parse reads preview metadata from the head of an HTML document. OpenGraph
+// tags win over Twitter card tags, which win over <title> and the plain
+// description meta tag. Relative image URLs are resolved against base.
+func Parse(r io.Reader, base *url.URL) Preview {
+	found := map[string]string{}
+	var title strings.Builder
+	inTitle := false
+
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return buildPreview(found, title.String(), base)
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return buildPreview(found, title.String(), base)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = tt == html.StartTagToken
+			case atom.Body:
+				return buildPreview(found, title.String(), base)
+			case atom.Meta:
+				var key, content string
+				for hasAttr {
+					var k, v []byte
+					k, v, hasAttr = z.TagAttr()
+					switch string(k) {
+					case "property", "name":
+						key = strings.ToLower(strings.TrimSpace(string(v)))
+					case "content":
+						content = string(v)
+					}
+				}
+				if _, ok := found[key]; key != "" && !ok {
+					found[key] = content
+				}
+			}
+		}
+	}
+}
+
+func buildPreview(found map[string]string, title string, base *url.URL) Preview {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if v := clean(found[key]); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	preview := Preview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+	}
+	if preview.Title == "" {
+		preview.Title = clean(title)
+	}
+	preview.Title = truncate(preview.Title, maxTitleLength)
+	preview.Description = truncate(preview.Description, maxDescriptionLength)
+	if base != nil {
+		preview.URL = base.String()
+		image := first("og:image:secure_url", "og:image:url", "og:image", "twitter:image", "twitter:image:src")
+		if ref, err := url.Parse(image); image != "" && err == nil {
+			resolved := base.ResolveReference(ref)
+			if (resolved.Scheme == "http" || resolved.Scheme == "https") && len(resolved.String()) <= maxURLLength {
+				preview.ImageURL = resolved.String()
+			}
+		}
+	}
+	return preview
+}
+
+// clean collapses the whitespace pages tend to leave in their metadata.
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,153 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFirstURL(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+		ok   bool
+	}{
+		{"no links here", "", false},
+		{"see https://example.com/a?b=c.", "https://example.com/a?b=c", true},
+		{"(http://example.com/x) and https://other.example", "http://example.com/x", true},
+		{"broken https:// then https://example.org", "https://example.org", true},
+	}
+	for _, tt := range tests {
+		got, ok := FirstURL(tt.body)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FirstURL(%q) = %q, %v; want %q, %v", tt.body, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/1")
+	page := `<!doctype html><html><head>
+		<title>Fallback   title</title>
+		<meta name="twitter:title" content="Twitter title">
+		<meta property="og:title" content="OG &amp; title">
+		<meta name="description" content="Plain description">
+		<meta name="twitter:description" content="  Card
+			description ">
+		<meta property="og:image" content="/images/cover.png">
+		</head><body><meta property="og:description" content="ignored"></body></html>`
+
+	got := Parse(strings.NewReader(page), base)
+	want := Preview{
+		URL:         "https://example.com/posts/1",
+		Title:       "OG & title",
+		Description: "Card description",
+		ImageURL:    "https://example.com/images/cover.png",
+	}
+	if got != want {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFallsBackToTitle(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	page := `<html><head><title>Just a title</title>
+		<meta property="og:image" content="javascript:alert(1)"></head></html>`
+	got := Parse(strings.NewReader(page), base)
+	if got.Title != "Just a title" || got.ImageURL != "" {
+		t.Errorf("Parse() = %+v", got)
+	}
+}
+
+func TestParseTruncatesLongTitles(t *testing.T) {
+	page := `<meta property="og:title" content="` + strings.Repeat("é", maxTitleLength+50) + `">`
+	got := Parse(strings.NewReader(page), nil)
+	if n := len([]rune(got.Title)); n != maxTitleLength {
+		t.Errorf("title has %d characters, want %d", n, maxTitleLength)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<head><meta property="og:title" content="Hello"></head>`))
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/huge":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(strings.Repeat(" ", 2048) + `<meta property="og:title" content="Too late">`))
+		case "/moved":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	fetcher := &Fetcher{Client: server.Client(), MaxBytes: 1024}
+	ctx := context.Background()
+
+	got, err := fetcher.Fetch(ctx, server.URL+"/moved")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got.Title != "Hello" || got.URL != server.URL+"/page" {
+		t.Errorf("Fetch() = %+v", got)
+	}
+	if _, err := fetcher.Fetch(ctx, server.URL+"/json"); !errors.Is(err, ErrNotHTML) {
+		t.Errorf("JSON response: got %v, want ErrNotHTML", err)
+	}
+	if _, err := fetcher.Fetch(ctx, server.URL+"/huge"); !errors.Is(err, ErrNoPreview) {
+		t.Errorf("oversized response: got %v, want ErrNoPreview", err)
+	}
+	if _, err := fetcher.Fetch(ctx, server.URL+"/missing"); err == nil {
+		t.Error("expected an error for a 404")
+	}
+	if _, err := fetcher.Fetch(ctx, "file:///etc/passwd"); err == nil {
+		t.Error("expected an error for a file URL")
+	}
+}
+
+func TestNewClientBlocksLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should never reach the server")
+	}))
+	defer server.Close()
+	fetcher := &Fetcher{Client: NewClient(DefaultTimeout)}
+	if _, err := fetcher.Fetch(context.Background(), server.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("got %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+		"::ffff:8.8.8.8":   true,
+		"64:ff9b::a00:1":   false,
+		"224.0.0.1":        false,
+	}
+	for addr, want := range tests {
+		if got := IsPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package media checks uploaded images and makes their thumbnails.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	// MaxBytes is the largest upload accepted.
+	MaxBytes = 10 << 20
+	// MaxPixels guards against images that are small on disk but huge
+	// once decoded.
+	MaxPixels = 40_000_000
+	// ThumbnailSize is the longest side of a thumbnail.
+	ThumbnailSize = 400
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported media type")
+	ErrTooManyPixels   = errors.New("image dimensions are too large")
+)
+
+type decoder struct {
+	decode       func(*bytes.Reader) (image.Image, error)
+	decodeConfig func(*bytes.Reader) (image.Config, error)
+}
+
+var decoders = map[string]decoder{
+	"image/jpeg": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+	},
+	"image/png": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+	},
+	"image/gif": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return gif.DecodeConfig(r) },
+	},
+	"image/webp": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+	},
+}
+
+// Image describes a checked upload and carries its encoded thumbnail.
+type Image struct {
+	ContentType          string
+	Width                int
+	Height               int
+	Thumbnail            []byte
+	ThumbnailContentType string
+}
+
+// Process sniffs the content type of data rather than trusting what the
+// client claimed, checks the image decodes within the size limits and
+// renders a thumbnail. JPEGs get JPEG thumbnails; everything else gets PNG
+// so transparency survives.
+func Process(data []byte) (Image, error) {
+	contentType := http.DetectContentType(data)
+	dec, ok := decoders[contentType]
+	if !ok {
+		return Image{}, ErrUnsupportedType
+	}
+	config, err := dec.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return Image{}, ErrTooManyPixels
+	}
+	img, err := dec.decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, err
+	}
+
+	var thumb bytes.Buffer
+	thumbType := "image/png"
+	if contentType == "image/jpeg" {
+		thumbType = "image/jpeg"
+		err = jpeg.Encode(&thumb, Thumbnail(img, ThumbnailSize), &jpeg.Options{Quality: 80})
+	} else {
+		err = png.Encode(&thumb, Thumbnail(img, ThumbnailSize))
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	return Image{
+		ContentType:          contentType,
+		Width:                config.Width,
+		Height:               config.Height,
+		Thumbnail:            thumb.Bytes(),
+		ThumbnailContentType: thumbType,
+	}, nil
+}
+
+// Thumbnail scales img down so its longest side is at most size, keeping
+// the aspect ratio. Images that already fit are returned unchanged.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*size/w)
+		w = size
+	} else {
+		w = max(1, w*size/h)
+		h = size
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
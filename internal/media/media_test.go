@@ -0,0 +1,86 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessPNG(t *testing.T) {
+	got, err := Process(encodePNG(t, 1200, 600))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if got.ContentType != "image/png" || got.Width != 1200 || got.Height != 600 {
+		t.Errorf("got %s %dx%d", got.ContentType, got.Width, got.Height)
+	}
+	thumb, err := png.Decode(bytes.NewReader(got.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail doesn't decode: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != ThumbnailSize || b.Dy() != ThumbnailSize/2 {
+		t.Errorf("thumbnail is %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestProcessJPEGThumbnail(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 300, 900)), nil); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := Process(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if got.ContentType != "image/jpeg" || got.ThumbnailContentType != "image/jpeg" {
+		t.Errorf("got %s with %s thumbnail", got.ContentType, got.ThumbnailContentType)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(got.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail doesn't decode: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != 133 || b.Dy() != ThumbnailSize {
+		t.Errorf("thumbnail is %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestProcessRejectsNonImages(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("just some text"),
+		[]byte("<html><body>hi</body></html>"),
+		[]byte("%PDF-1.4 not an image"),
+	} {
+		if _, err := Process(data); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Process(%q) = %v, want ErrUnsupportedType", data, err)
+		}
+	}
+}
+
+func TestProcessRejectsTruncatedImage(t *testing.T) {
+	data := encodePNG(t, 50, 50)
+	if _, err := Process(data[:len(data)/2]); err == nil {
+		t.Error("expected truncated image to fail")
+	}
+}
+
+func TestThumbnailLeavesSmallImages(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 20))
+	if got := Thumbnail(img, ThumbnailSize); got != image.Image(img) {
+		t.Error("small image should be returned as is")
+	}
+}
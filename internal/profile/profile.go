@@ -0,0 +1,101 @@
+// Package profile checks the fields users fill in on their public profile.
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MinHandleLength      = 3
+	MaxHandleLength      = 30
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 300
+	MaxURLLength         = 200
+)
+
+// reservedHandles would clash with routes or pass for staff.
+var reservedHandles = map[string]bool{
+	"me":        true,
+	"admin":     true,
+	"api":       true,
+	"chirpy":    true,
+	"moderator": true,
+	"support":   true,
+}
+
+// NormalizeHandle checks a handle and returns it without a leading "@".
+// Handles are ASCII letters, digits and underscores, so they're safe in
+// URLs and can't be mistaken for user IDs. Case is kept for display;
+// uniqueness ignores it.
+func NormalizeHandle(handle string) (string, error) {
+	handle = strings.TrimPrefix(strings.TrimSpace(handle), "@")
+	if len(handle) < MinHandleLength || len(handle) > MaxHandleLength {
+		return "", fmt.Errorf("handle must be %d to %d characters", MinHandleLength, MaxHandleLength)
+	}
+	for _, r := range handle {
+		if !isHandleRune(r) {
+			return "", errors.New("handle may only contain letters, digits and underscores")
+		}
+	}
+	if reservedHandles[strings.ToLower(handle)] {
+		return "", errors.New("handle is reserved")
+	}
+	return handle, nil
+}
+
+func isHandleRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// NormalizeDisplayName trims a display name and checks its length. An
+// empty display name is allowed.
+func NormalizeDisplayName(name string) (string, error) {
+	return normalizeText(name, "display name", MaxDisplayNameLength, false)
+}
+
+// NormalizeBio trims a bio and checks its length. Unlike display names,
+// bios may span lines.
+func NormalizeBio(bio string) (string, error) {
+	return normalizeText(bio, "bio", MaxBioLength, true)
+}
+
+func normalizeText(s, field string, maxLength int, multiline bool) (string, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	if utf8.RuneCountInString(s) > maxLength {
+		return "", fmt.Errorf("%s must be at most %d characters", field, maxLength)
+	}
+	for _, r := range s {
+		if r == '\n' && multiline {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("%s must not contain control characters", field)
+		}
+	}
+	return s, nil
+}
+
+// NormalizeURL checks a website or avatar link. Only absolute http and
+// https URLs are accepted; an empty string clears the field.
+func NormalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if len(raw) > MaxURLLength {
+		return "", fmt.Errorf("URL must be at most %d characters", MaxURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", errors.New("URL must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return "", errors.New("URL must not contain credentials")
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// Query is a parsed search string. Text keeps everything that is not an
+// operator, including quoted phrases, in a form websearch_to_tsquery accepts.
+type Query struct {
+	Text  string
+	From  uuid.NullUUID
+	Since time.Time
+	Until time.Time
+}
+
+// Parse splits a search string into free text and the operators
+//
+//	from:<user_id>   only chirps by that user
+//	since:<date>     chirps created on or after the date
+//	until:<date>     chirps created on or before the date
+//
+// where dates are YYYY-MM-DD or RFC 3339 timestamps. Quoted phrases are
+// passed through untouched, so an operator inside quotes is searched as text.
+func Parse(raw string) (Query, error) {
+	q := Query{}
+	text := []string{}
+	for _, token := range tokenize(raw) {
+		name, value, ok := strings.Cut(token, ":")
+		if !ok || strings.HasPrefix(token, `"`) || value == "" {
+			text = append(text, token)
+			continue
+		}
+		switch strings.ToLower(name) {
+		case "from":
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid from: %q", value)
+			}
+			q.From = uuid.NullUUID{UUID: id, Valid: true}
+		case "since":
+			t, _, err := parseDate(value)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid since: %q", value)
+			}
+			q.Since = t
+		case "until":
+			t, dateOnly, err := parseDate(value)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid until: %q", value)
+			}
+			if dateOnly {
+				// until:2024-01-31 includes the whole of the 31st.
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			q.Until = t
+		default:
+			text = append(text, token)
+		}
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return Query{}, fmt.Errorf("until is before since")
+	}
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+func parseDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t.UTC(), false, nil
+}
+
+// tokenize splits on whitespace but keeps double-quoted phrases, quotes
+// included, as single tokens. An unterminated quote runs to the end.
+func tokenize(raw string) []string {
+	tokens := []string{}
+	var current strings.Builder
+	inQuotes := false
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			if inQuotes {
+				current.WriteRune(r)
+				flush()
+				inQuotes = false
+				continue
+			}
+			flush()
+			inQuotes = true
+			current.WriteRune(r)
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		current.WriteRune('"')
+	}
+	flush()
+	return tokens
+}
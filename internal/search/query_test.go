@@ -0,0 +1,85 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParse(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Plain Text", func(t *testing.T) {
+		q, err := Parse("  hello   world ")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if q.Text != "hello world" {
+			t.Errorf("expected text 'hello world', got '%s'", q.Text)
+		}
+		if q.From.Valid || !q.Since.IsZero() || !q.Until.IsZero() {
+			t.Errorf("expected no operators, got %+v", q)
+		}
+	})
+
+	t.Run("Quoted Phrase Kept", func(t *testing.T) {
+		q, err := Parse(`"from:nobody here" go`)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if q.Text != `"from:nobody here" go` {
+			t.Errorf("unexpected text '%s'", q.Text)
+		}
+		if q.From.Valid {
+			t.Error("expected operator inside quotes to be treated as text")
+		}
+	})
+
+	t.Run("Unterminated Quote", func(t *testing.T) {
+		q, err := Parse(`"open phrase`)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if q.Text != `"open phrase"` {
+			t.Errorf("unexpected text '%s'", q.Text)
+		}
+	})
+
+	t.Run("Operators", func(t *testing.T) {
+		q, err := Parse("gophers from:" + userID.String() + " since:2024-01-01 until:2024-01-31")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if q.Text != "gophers" {
+			t.Errorf("expected text 'gophers', got '%s'", q.Text)
+		}
+		if !q.From.Valid || q.From.UUID != userID {
+			t.Errorf("expected from %v, got %v", userID, q.From)
+		}
+		if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !q.Since.Equal(want) {
+			t.Errorf("expected since %v, got %v", want, q.Since)
+		}
+		if want := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond); !q.Until.Equal(want) {
+			t.Errorf("expected until %v, got %v", want, q.Until)
+		}
+	})
+
+	t.Run("Unknown Operator Is Text", func(t *testing.T) {
+		q, err := Parse("lang:go")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if q.Text != "lang:go" {
+			t.Errorf("expected text 'lang:go', got '%s'", q.Text)
+		}
+	})
+
+	t.Run("Invalid Operators", func(t *testing.T) {
+		for _, raw := range []string{"from:bob", "since:yesterday", "until:2024-13-01", "since:2024-02-01 until:2024-01-01"} {
+			if _, err := Parse(raw); err == nil {
+				t.Errorf("expected error for %q but got none", raw)
+			}
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	if err := store.Put(ctx, "media/abc.png", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	f, err := store.Open(ctx, "media/abc.png")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("read %q, %v", data, err)
+	}
+
+	if err := store.Delete(ctx, "media/abc.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Open(ctx, "media/abc.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "media/abc.png"); err != nil {
+		t.Errorf("deleting a missing key should succeed, got %v", err)
+	}
+}
+
+func TestLocalRejectsBadKeys(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	for _, key := range []string{"", "../escape", "a/../../b", "/abs", "a//b", ".hidden", "sp ace"} {
+		if err := store.Put(ctx, key, strings.NewReader("x")); err == nil {
+			t.Errorf("Put(%q) should fail", key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package stream fans events out to live subscribers, such as clients of a
+// Server-Sent Events endpoint. Publishing never blocks: a subscriber whose
+// buffer fills up is dropped and has to reconnect.
+package stream
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Event is one message for subscribers. AuthorID is what subscriptions can
+// filter on.
+type Event struct {
+	ID       int64
+	Name     string
+	AuthorID uuid.UUID
+	Data     []byte
+}
+
+// Hub delivers published events to its subscribers.
+type Hub struct {
+	buffer int
+
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// NewHub returns a hub that buffers up to buffer events per subscriber.
+func NewHub(buffer int) *Hub {
+	return &Hub{buffer: buffer, subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives events on C until it's closed, or until the hub
+// drops it for falling behind, which closes C.
+type Subscription struct {
+	C <-chan Event
+
+	c        chan Event
+	authorID uuid.UUID
+	hub      *Hub
+}
+
+// Subscribe starts a subscription to events by authorID, or to every event
+// when authorID is uuid.Nil.
+func (h *Hub) Subscribe(authorID uuid.UUID) *Subscription {
+	c := make(chan Event, h.buffer)
+	sub := &Subscription{C: c, c: c, authorID: authorID, hub: h}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Publish hands e to every matching subscriber without waiting on any of
+// them.
+func (h *Hub) Publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if sub.authorID != uuid.Nil && sub.authorID != e.AuthorID {
+			continue
+		}
+		select {
+		case sub.c <- e:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribers returns how many subscriptions are open.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close ends the subscription. It's safe to call more than once, and after
+// the hub has dropped it.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.c)
+}
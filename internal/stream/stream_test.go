@@ -0,0 +1,65 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPublishFiltersByAuthor(t *testing.T) {
+	hub := NewHub(4)
+	alice, bob := uuid.New(), uuid.New()
+	all := hub.Subscribe(uuid.Nil)
+	onlyAlice := hub.Subscribe(alice)
+	defer all.Close()
+	defer onlyAlice.Close()
+
+	hub.Publish(Event{ID: 1, AuthorID: alice})
+	hub.Publish(Event{ID: 2, AuthorID: bob})
+
+	if got := len(all.C); got != 2 {
+		t.Errorf("unfiltered subscription got %d events, want 2", got)
+	}
+	if got := len(onlyAlice.C); got != 1 {
+		t.Fatalf("filtered subscription got %d events, want 1", got)
+	}
+	if e := <-onlyAlice.C; e.ID != 1 {
+		t.Errorf("filtered subscription got event %d, want 1", e.ID)
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub(2)
+	slow := hub.Subscribe(uuid.Nil)
+	fast := hub.Subscribe(uuid.Nil)
+	defer fast.Close()
+
+	for i := int64(1); i <= 3; i++ {
+		hub.Publish(Event{ID: i})
+		<-fast.C
+	}
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("slow subscriber got %d events before being dropped, want 2", received)
+	}
+	if got := hub.Subscribers(); got != 1 {
+		t.Errorf("hub has %d subscribers, want 1", got)
+	}
+	// Closing after being dropped is harmless.
+	slow.Close()
+}
+
+func TestCloseIsIdempotent(t *testing.T) {
+	hub := NewHub(1)
+	sub := hub.Subscribe(uuid.Nil)
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("channel still open after Close")
+	}
+	hub.Publish(Event{ID: 1})
+}
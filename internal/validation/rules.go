@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/cygran/chirpy/internal/wordfilter"
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxBlankLines = 2
+
+// Whitespace trims the body, collapses runs of spaces and tabs, caps blank
+// lines and drops invisible characters. Zero-width joiners are kept because
+// emoji sequences depend on them.
+type Whitespace struct{}
+
+func (Whitespace) Validate(c *Chirp) []Violation {
+	var b strings.Builder
+	pendingSpace, newlines := false, 0
+	for _, r := range strings.ReplaceAll(c.Body, "\r\n", "\n") {
+		switch {
+		case isInvisible(r):
+			continue
+		case r == '\n' || r == '\r':
+			pendingSpace = false
+			newlines++
+			continue
+		case unicode.IsSpace(r):
+			pendingSpace = true
+			continue
+		}
+		if b.Len() > 0 {
+			if newlines > 0 {
+				b.WriteString(strings.Repeat("\n", min(newlines, maxBlankLines)))
+			} else if pendingSpace {
+				b.WriteByte(' ')
+			}
+		}
+		pendingSpace, newlines = false, 0
+		b.WriteRune(r)
+	}
+	c.Body = b.String()
+	return nil
+}
+
+func isInvisible(r rune) bool {
+	switch r {
+	case '\u200b', '\u2060', '\ufeff', '\u00ad', '\u180e':
+		return true
+	}
+	return false
+}
+
+// NFC puts the body in Unicode Normalization Form C, so the same text
+// always has the same bytes no matter how it was typed.
+type NFC struct{}
+
+func (NFC) Validate(c *Chirp) []Violation {
+	c.Body = norm.NFC.String(c.Body)
+	return nil
+}
+
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// Length limits the body to Max user-perceived characters (grapheme
+// clusters), so an emoji counts once however many code points it takes.
+// When URLLength is set each link counts as that many characters instead.
+type Length struct {
+	Max       int
+	URLLength int
+}
+
+func (l Length) Validate(c *Chirp) []Violation {
+	length := Count(c.Body, l.URLLength)
+	if length <= l.Max {
+		return nil
+	}
+	return []Violation{{
+		Field:   "body",
+		Rule:    RuleLength,
+		Message: fmt.Sprintf("Chirp is too long (%d/%d)", length, l.Max),
+	}}
+}
+
+// Count returns how long body is under the length rule.
+func Count(body string, urlLength int) int {
+	if urlLength <= 0 {
+		return uniseg.GraphemeClusterCount(body)
+	}
+	length, last := 0, 0
+	for _, loc := range urlPattern.FindAllStringIndex(body, -1) {
+		length += uniseg.GraphemeClusterCount(body[last:loc[0]]) + urlLength
+		last = loc[1]
+	}
+	return length + uniseg.GraphemeClusterCount(body[last:])
+}
+
+// Words runs the body through the word filter: masked words are replaced,
+// rejected words are violations and flagged words are recorded on the chirp.
+type Words struct {
+	Filter *wordfilter.Filter
+}
+
+func (w Words) Validate(c *Chirp) []Violation {
+	result := w.Filter.Apply(c.Body)
+	c.Body = result.Body
+	c.Flagged = append(c.Flagged, result.Flagged...)
+	if len(result.Rejected) == 0 {
+		return nil
+	}
+	return []Violation{{
+		Field:   "body",
+		Rule:    RuleWords,
+		Message: "Chirp contains blocked words: " + strings.Join(result.Rejected, ", "),
+	}}
+}
@@ -0,0 +1,104 @@
+// Package validation checks and normalizes chirp bodies through an ordered
+// chain of rules.
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cygran/chirpy/internal/wordfilter"
+)
+
+// Violation is one reason a chirp was refused.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Chirp is the chirp being validated. Rules that normalize the body rewrite
+// it in place, so later rules see the result of earlier ones.
+type Chirp struct {
+	Body string
+	// Flagged holds the filtered words that should send the chirp to review.
+	Flagged []string
+}
+
+// ChirpValidator is a single validation rule, or a chain of them.
+type ChirpValidator interface {
+	Validate(c *Chirp) []Violation
+}
+
+// Chain runs its validators in order and collects every violation.
+type Chain []ChirpValidator
+
+func (ch Chain) Validate(c *Chirp) []Violation {
+	var violations []Violation
+	for _, v := range ch {
+		violations = append(violations, v.Validate(c)...)
+	}
+	return violations
+}
+
+// Rule names accepted in Config.Rules.
+const (
+	RuleWhitespace = "whitespace"
+	RuleNFC        = "nfc"
+	RuleLength     = "length"
+	RuleWords      = "words"
+)
+
+// DefaultRules is the rule order used when a deployment doesn't pick one.
+var DefaultRules = []string{RuleWhitespace, RuleNFC, RuleLength, RuleWords}
+
+// Config describes the rules a deployment runs.
+type Config struct {
+	Rules     []string
+	MaxLength int
+	// URLLength is how many characters a link counts as, however long it
+	// is. Zero counts links at their actual length.
+	URLLength int
+}
+
+// DefaultConfig matches the limits chirps have always had.
+func DefaultConfig() Config {
+	return Config{
+		Rules:     DefaultRules,
+		MaxLength: 140,
+	}
+}
+
+// ParseRules reads a comma-separated, ordered list of rule names.
+func ParseRules(s string) ([]string, error) {
+	rules := []string{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case RuleWhitespace, RuleNFC, RuleLength, RuleWords:
+			rules = append(rules, name)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	return rules, nil
+}
+
+// Build assembles the chain for this config. The word filter changes at
+// runtime, so the chain is built with whichever filter is current.
+func (c Config) Build(filter *wordfilter.Filter) Chain {
+	chain := make(Chain, 0, len(c.Rules))
+	for _, name := range c.Rules {
+		switch name {
+		case RuleWhitespace:
+			chain = append(chain, Whitespace{})
+		case RuleNFC:
+			chain = append(chain, NFC{})
+		case RuleLength:
+			chain = append(chain, Length{Max: c.MaxLength, URLLength: c.URLLength})
+		case RuleWords:
+			chain = append(chain, Words{Filter: filter})
+		}
+	}
+	return chain
+}
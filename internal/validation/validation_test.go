@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cygran/chirpy/internal/wordfilter"
+)
+
+func TestWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"Unchanged", "hello world", "hello world"},
+		{"Trimmed", "  hello world \t", "hello world"},
+		{"Collapsed Spaces", "hello \t  world", "hello world"},
+		{"Blank Lines Capped", "hello\r\n\n\n\n\nworld", "hello\n\nworld"},
+		{"Zero Width Dropped", "ker\u200bfuf\ufefffle", "kerfuffle"},
+		{"Emoji Joiner Kept", "family \U0001F468\u200d\U0001F469\u200d\U0001F467", "family \U0001F468\u200d\U0001F469\u200d\U0001F467"},
+		{"Only Whitespace", " \n\t ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Chirp{Body: tt.body}
+			Whitespace{}.Validate(c)
+			if c.Body != tt.want {
+				t.Errorf("Whitespace(%q) = %q, want %q", tt.body, c.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestNFC(t *testing.T) {
+	c := &Chirp{Body: "cafe\u0301"}
+	NFC{}.Validate(c)
+	if c.Body != "caf\u00e9" {
+		t.Errorf("NFC left %q", c.Body)
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		urlLength int
+		want      int
+	}{
+		{"ASCII", "hello", 0, 5},
+		{"Emoji", strings.Repeat("😀", 50), 0, 50},
+		{"Flag And Family", "\U0001F1F3\U0001F1F1\U0001F468\u200d\U0001F469\u200d\U0001F467", 0, 2},
+		{"Combining Mark", "cafe\u0301", 0, 4},
+		{"URL Unweighted", "see https://example.com/a", 0, 25},
+		{"URL Weighted", "see https://example.com/a/very/long/path and more", 23, 36},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.body, tt.urlLength); got != tt.want {
+				t.Errorf("Count(%q, %d) = %d, want %d", tt.body, tt.urlLength, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	filter := wordfilter.New([]wordfilter.Rule{
+		{Word: "kerfuffle", Action: wordfilter.ActionMask},
+		{Word: "fornax", Action: wordfilter.ActionReject},
+		{Word: "sharbert", Action: wordfilter.ActionFlag},
+	})
+	chain := DefaultConfig().Build(filter)
+
+	c := &Chirp{Body: "  what a Ker\u200bfuffle,   sharbert "}
+	if violations := chain.Validate(c); len(violations) != 0 {
+		t.Fatalf("unexpected violations: %v", violations)
+	}
+	if c.Body != "what a ****, sharbert" {
+		t.Errorf("body = %q", c.Body)
+	}
+	if !slices.Equal(c.Flagged, []string{"sharbert"}) {
+		t.Errorf("flagged = %v", c.Flagged)
+	}
+
+	c = &Chirp{Body: "fornax " + strings.Repeat("a", 140)}
+	violations := chain.Validate(c)
+	rules := []string{}
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	if !slices.Equal(rules, []string{RuleLength, RuleWords}) {
+		t.Errorf("violation rules = %v", rules)
+	}
+
+	c = &Chirp{Body: strings.Repeat("😀", 140)}
+	if violations := chain.Validate(c); len(violations) != 0 {
+		t.Errorf("140 emoji should fit, got %v", violations)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("nfc, length,,words")
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+	if !slices.Equal(rules, []string{RuleNFC, RuleLength, RuleWords}) {
+		t.Errorf("rules = %v", rules)
+	}
+	if _, err := ParseRules("length,spellcheck"); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+}
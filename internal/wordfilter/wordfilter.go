@@ -0,0 +1,149 @@
+// Package wordfilter matches chirp bodies against a list of filtered words.
+package wordfilter
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+)
+
+// Mask is what a masked word is replaced with.
+const Mask = "****"
+
+// Action is what happens to a chirp that contains a filtered word.
+type Action string
+
+const (
+	// ActionMask replaces the word with Mask.
+	ActionMask Action = "mask"
+	// ActionReject refuses the chirp outright.
+	ActionReject Action = "reject"
+	// ActionFlag lets the chirp through but marks it for review.
+	ActionFlag Action = "flag"
+)
+
+// ParseAction checks that s names a known action.
+func ParseAction(s string) (Action, error) {
+	switch action := Action(s); action {
+	case ActionMask, ActionReject, ActionFlag:
+		return action, nil
+	}
+	return "", errors.New("action must be one of mask, reject or flag")
+}
+
+// Rule pairs a filtered word with its action.
+type Rule struct {
+	Word   string
+	Action Action
+}
+
+// Result is the outcome of running a body through a Filter. Rejected and
+// Flagged hold the normalized words that triggered those actions.
+type Result struct {
+	Body     string
+	Rejected []string
+	Flagged  []string
+}
+
+// Filter is a compiled set of rules. The zero value filters nothing.
+type Filter struct {
+	rules map[string]Action
+}
+
+// New compiles rules into a Filter. Words are normalized, so rules that
+// differ only in case collapse into one; the last one wins.
+func New(rules []Rule) *Filter {
+	f := &Filter{rules: make(map[string]Action, len(rules))}
+	for _, rule := range rules {
+		f.rules[fold(rule.Word)] = rule.Action
+	}
+	return f
+}
+
+// Normalize returns the form a word is stored and matched in. Words have to
+// be a single run of letters and digits so they can only ever match whole
+// words in a chirp.
+func Normalize(word string) (string, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return "", errors.New("word is empty")
+	}
+	if strings.IndexFunc(word, isSeparator) >= 0 {
+		return "", errors.New("word must not contain spaces or punctuation")
+	}
+	return fold(word), nil
+}
+
+// Apply checks every word in body. Words are the runs of letters and digits
+// between spaces and punctuation, compared with Unicode case folding, so
+// "Kerfuffle!" matches a rule for "kerfuffle".
+func (f *Filter) Apply(body string) Result {
+	result := Result{Body: body}
+	if f == nil || len(f.rules) == 0 {
+		return result
+	}
+	var (
+		b       strings.Builder
+		last    int
+		matched bool
+	)
+	seen := map[string]bool{}
+	for _, span := range words(body) {
+		word := fold(body[span[0]:span[1]])
+		action, ok := f.rules[word]
+		if !ok {
+			continue
+		}
+		switch action {
+		case ActionMask:
+			b.WriteString(body[last:span[0]])
+			b.WriteString(Mask)
+			last = span[1]
+			matched = true
+		case ActionReject:
+			if !seen[word] {
+				result.Rejected = append(result.Rejected, word)
+			}
+		case ActionFlag:
+			if !seen[word] {
+				result.Flagged = append(result.Flagged, word)
+			}
+		}
+		seen[word] = true
+	}
+	if matched {
+		b.WriteString(body[last:])
+		result.Body = b.String()
+	}
+	return result
+}
+
+// words returns the byte offsets of each word in s.
+func words(s string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range s {
+		if isSeparator(r) {
+			if start >= 0 {
+				spans = append(spans, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(s)})
+	}
+	return spans
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+}
+
+func fold(s string) string {
+	return cases.Fold().String(s)
+}
@@ -0,0 +1,77 @@
+package wordfilter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	filter := New([]Rule{
+		{Word: "kerfuffle", Action: ActionMask},
+		{Word: "Sharbert", Action: ActionMask},
+		{Word: "fornax", Action: ActionReject},
+		{Word: "straße", Action: ActionFlag},
+	})
+	tests := []struct {
+		name         string
+		body         string
+		wantBody     string
+		wantRejected []string
+		wantFlagged  []string
+	}{
+		{"Clean", "just a normal chirp", "just a normal chirp", nil, nil},
+		{"Masked", "what a kerfuffle", "what a ****", nil, nil},
+		{"Trailing Punctuation", "Kerfuffle! and sharbert,", "****! and ****,", nil, nil},
+		{"Case Folding", "KERFUFFLE SharBert", "**** ****", nil, nil},
+		{"Inside Word Ignored", "kerfuffles and sharberts", "kerfuffles and sharberts", nil, nil},
+		{"Rejected", "fornax, twice: Fornax", "fornax, twice: Fornax", []string{"fornax"}, nil},
+		{"Flagged Full Folding", "STRASSE ahead", "STRASSE ahead", nil, []string{"strasse"}},
+		{"Mixed", "kerfuffle near the straße", "**** near the straße", nil, []string{"strasse"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filter.Apply(tt.body)
+			if got.Body != tt.wantBody {
+				t.Errorf("Apply(%q).Body = %q, want %q", tt.body, got.Body, tt.wantBody)
+			}
+			if !slices.Equal(got.Rejected, tt.wantRejected) {
+				t.Errorf("Apply(%q).Rejected = %v, want %v", tt.body, got.Rejected, tt.wantRejected)
+			}
+			if !slices.Equal(got.Flagged, tt.wantFlagged) {
+				t.Errorf("Apply(%q).Flagged = %v, want %v", tt.body, got.Flagged, tt.wantFlagged)
+			}
+		})
+	}
+}
+
+func TestApplyNilFilter(t *testing.T) {
+	var filter *Filter
+	if got := filter.Apply("kerfuffle"); got.Body != "kerfuffle" {
+		t.Errorf("nil filter changed body to %q", got.Body)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		word    string
+		want    string
+		wantErr bool
+	}{
+		{"Kerfuffle", "kerfuffle", false},
+		{"  fornax ", "fornax", false},
+		{"Straße", "strasse", false},
+		{"", "", true},
+		{"two words", "", true},
+		{"bang!", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.word)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Normalize(%q) error = %v, wantErr %v", tt.word, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
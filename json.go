@@ -1,9 +1,13 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 )
 
 func respondWithError(w http.ResponseWriter, code int, msg string, err error) {
@@ -32,3 +36,70 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.WriteHeader(code)
 	w.Write(dat)
 }
+
+// respondWithCacheableJSON is respondWithJSON for reads that clients and the
+// CDN may revalidate. The ETag is a hash of the body, so it changes with
+// anything in it, including the viewer's own likes and votes. lastModified
+// is the newest updated_at in the response; every write that changes a
+// chirp's JSON bumps its updated_at, but a list losing a chirp, or gaining
+// an older one, only shows in the ETag. Conditional requests that still
+// match get a 304 without the body.
+func respondWithCacheableJSON(w http.ResponseWriter, r *http.Request, payload interface{}, lastModified time.Time) {
+	dat, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshalling JSON: %s", err)
+		w.WriteHeader(500)
+		return
+	}
+	sum := sha256.Sum256(dat)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+
+	// Responses to signed-in users carry their own likes and votes, so only
+	// anonymous ones may be shared. Either way caches must check back
+	// before reusing a response.
+	w.Header().Set("Vary", "Authorization")
+	if r.Header.Get("Authorization") != "" {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	} else {
+		w.Header().Set("Cache-Control", "public, no-cache")
+	}
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(dat)
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since the way RFC 9110
+// orders them: when If-None-Match is present If-Modified-Since is ignored.
+// A zero lastModified never matches.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// Last-Modified only has whole seconds.
+	return !lastModified.Truncate(time.Second).After(ims)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type Like struct {
+	UserID  uuid.UUID `json:"user_id"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+func (cfg *apiConfig) likeChirpHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, true)
+}
+
+func (cfg *apiConfig) unlikeChirpHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, false)
+}
+
+// setChirpLike adds or removes the caller's like. Repeating either request
+// is a no-op, and the like count only moves when the like itself changes.
+// Liking a plain rechirp likes the original.
+func (cfg *apiConfig) setChirpLike(w http.ResponseWriter, r *http.Request, liked bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := cfg.viewableChirp(r, qtx, id)
+	if err == nil && chirp.RechirpOfID.Valid {
+		chirp, err = cfg.viewableChirp(r, qtx, chirp.RechirpOfID.UUID)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if chirp.TombstonedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+
+	likeParams := database.LikeChirpParams{ChirpID: chirp.ID, UserID: validatedUserID}
+	if liked {
+		changed, err := qtx.LikeChirp(r.Context(), likeParams)
+		if err == nil && changed > 0 {
+			chirp, err = qtx.IncrementLikeCount(r.Context(), chirp.ID)
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't like chirp", err)
+			return
+		}
+	} else {
+		changed, err := qtx.UnlikeChirp(r.Context(), database.UnlikeChirpParams(likeParams))
+		if err == nil && changed > 0 {
+			chirp, err = qtx.DecrementLikeCount(r.Context(), chirp.ID)
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't unlike chirp", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update like", err)
+		return
+	}
+
+	response := chirpFromDB(chirp)
+	response.LikedByMe = &liked
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *apiConfig) getChirpLikesHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	// A plain rechirp's likes are its original's.
+	chirp, err := cfg.viewableChirp(r, cfg.dbQueries, id)
+	if err == nil && chirp.RechirpOfID.Valid {
+		chirp, err = cfg.viewableChirp(r, cfg.dbQueries, chirp.RechirpOfID.UUID)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+
+	cursorCreatedAt, cursorUserID := page.cursorArgs()
+	dbLikes, err := cfg.dbQueries.GetChirpLikes(r.Context(), database.GetChirpLikesParams{
+		ChirpID:         chirp.ID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorUserID:    cursorUserID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve likes", err)
+		return
+	}
+
+	dbLikes, next, _ := paginate(dbLikes, page, func(like database.ChirpLike) pageCursor {
+		return pageCursor{CreatedAt: like.CreatedAt, ID: like.UserID}
+	})
+	likes := []Like{}
+	for _, dbLike := range dbLikes {
+		likes = append(likes, Like{
+			UserID:  dbLike.UserID,
+			LikedAt: dbLike.CreatedAt,
+		})
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, likes)
+}
+
+// markLikedByViewer fills in LikedByMe on each chirp for the given viewer.
+func (cfg *apiConfig) markLikedByViewer(ctx context.Context, viewerID uuid.UUID, chirps []Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		ids = append(ids, chirp.ID)
+	}
+	likedIDs, err := cfg.dbQueries.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{
+		UserID:   viewerID,
+		ChirpIds: ids,
+	})
+	if err != nil {
+		return err
+	}
+	liked := make(map[uuid.UUID]bool, len(likedIDs))
+	for _, id := range likedIDs {
+		liked[id] = true
+	}
+	for i := range chirps {
+		likedByMe := liked[chirps[i].ID]
+		chirps[i].LikedByMe = &likedByMe
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/linkpreview"
+	"github.com/google/uuid"
+)
+
+const (
+	linkPreviewWorkers   = 4
+	linkPreviewQueueSize = 256
+)
+
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url,omitempty"`
+}
+
+type linkPreviewJob struct {
+	chirpID uuid.UUID
+	body    string
+	url     string
+}
+
+// queueLinkPreview schedules a preview fetch for the first link in a chirp.
+// Call it once the chirp is committed. When the queue is full the preview is
+// skipped rather than holding up the request.
+func (cfg *apiConfig) queueLinkPreview(chirp database.Chirp) {
+	if cfg.linkPreviewJobs == nil {
+		return
+	}
+	url, ok := linkpreview.FirstURL(chirp.Body)
+	if !ok {
+		return
+	}
+	select {
+	case cfg.linkPreviewJobs <- linkPreviewJob{chirpID: chirp.ID, body: chirp.Body, url: url}:
+	default:
+		log.Printf("Link preview queue full, skipping chirp %s", chirp.ID)
+	}
+}
+
+// linkPreviewWorker fetches queued previews until ctx is done.
+func (cfg *apiConfig) linkPreviewWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-cfg.linkPreviewJobs:
+			if err := cfg.saveLinkPreview(ctx, job); err != nil {
+				log.Printf("Error fetching link preview for chirp %s: %s", job.chirpID, err)
+			}
+		}
+	}
+}
+
+// saveLinkPreview only stores the preview if the chirp hasn't been edited or
+// deleted while the page was loading; otherwise the preview is stale and
+// dropped. It compares the body rather than updated_at, which likes and
+// replies move too.
+func (cfg *apiConfig) saveLinkPreview(ctx context.Context, job linkPreviewJob) error {
+	preview, err := cfg.linkPreviewer.Fetch(ctx, job.url)
+	if err != nil {
+		return err
+	}
+	_, err = cfg.dbQueries.SaveLinkPreview(ctx, database.SaveLinkPreviewParams{
+		ChirpID:     job.chirpID,
+		ChirpBody:   job.body,
+		Url:         preview.URL,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageUrl:    preview.ImageURL,
+	})
+	return err
+}
+
+// embedLinkPreviews fills in the previews that have finished loading.
+func (cfg *apiConfig) embedLinkPreviews(ctx context.Context, chirps []Chirp) error {
+	targets := visibleChirps(chirps)
+	if len(targets) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(targets))
+	for _, chirp := range targets {
+		ids = append(ids, chirp.ID)
+	}
+	dbPreviews, err := cfg.dbQueries.GetLinkPreviews(ctx, ids)
+	if err != nil {
+		return err
+	}
+	previews := make(map[uuid.UUID]LinkPreview, len(dbPreviews))
+	for _, preview := range dbPreviews {
+		previews[preview.ChirpID] = LinkPreview{
+			URL:         preview.Url,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageUrl,
+		}
+	}
+	for _, chirp := range targets {
+		if preview, ok := previews[chirp.ID]; ok {
+			chirp.LinkPreview = &preview
+		}
+	}
+	return nil
+}
@@ -39,6 +39,10 @@ func (cfg *apiConfig) loginHandler(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
 		return
 	}
+	if isSuspended(user.AccountState, user.SuspendedUntil, time.Now().UTC()) {
+		respondWithSuspended(w, user.SuspendedUntil)
+		return
+	}
 	token, err := auth.MakeJWT(user.ID, cfg.jwtSecret)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Unable to generate token", err)
@@ -60,13 +64,7 @@ func (cfg *apiConfig) loginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	respondWithJSON(w, http.StatusOK, response{
-		User: User{
-			ID:        user.ID,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-			Email:     user.Email,
-			ChirpyRed: user.IsChirpyRed,
-		},
+		User:         userFromDB(user),
 		Token:        token,
 		RefreshToken: refreshToken,
 	})
@@ -1,25 +1,50 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/linkpreview"
+	"github.com/cygran/chirpy/internal/storage"
+	"github.com/cygran/chirpy/internal/stream"
+	"github.com/cygran/chirpy/internal/validation"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
+// filepathRoot holds the static files served under /app/.
+const filepathRoot = "static"
+
 func main() {
+	rebuildTimelines := flag.Bool("rebuild-timelines", false, "rebuild every user's timeline and exit")
+	flag.Parse()
 	godotenv.Load()
 	dbURL := os.Getenv("DB_URL")
 	if dbURL == "" {
 		log.Fatal("DB_URL must be set")
 	}
+	if *rebuildTimelines {
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg := &apiConfig{db: db, dbQueries: database.New(db)}
+		if err := cfg.rebuildAllTimelines(context.Background()); err != nil {
+			log.Fatalf("Couldn't rebuild timelines: %s", err)
+		}
+		return
+	}
 	platform := os.Getenv("PLATFORM")
 	if platform == "" {
 		log.Fatal("PLATFORM must be set")
@@ -32,6 +57,67 @@ func main() {
 	if polkaKey == "" {
 		log.Fatal("POLKA_KEY must be set")
 	}
+	// Optional: the admin API stays disabled without it.
+	adminKey := os.Getenv("ADMIN_KEY")
+	chirpEditWindow := defaultChirpEditWindow
+	if window := os.Getenv("CHIRP_EDIT_WINDOW"); window != "" {
+		parsed, err := time.ParseDuration(window)
+		if err != nil {
+			log.Fatalf("CHIRP_EDIT_WINDOW must be a duration: %s", err)
+		}
+		chirpEditWindow = parsed
+	}
+	trashRetention := defaultTrashRetention
+	if retention := os.Getenv("CHIRP_TRASH_RETENTION"); retention != "" {
+		parsed, err := time.ParseDuration(retention)
+		if err != nil {
+			log.Fatalf("CHIRP_TRASH_RETENTION must be a duration: %s", err)
+		}
+		trashRetention = parsed
+	}
+	chirpValidation := validation.DefaultConfig()
+	if rules := os.Getenv("CHIRP_RULES"); rules != "" {
+		parsed, err := validation.ParseRules(rules)
+		if err != nil {
+			log.Fatalf("CHIRP_RULES: %s", err)
+		}
+		chirpValidation.Rules = parsed
+	}
+	if maxLength := os.Getenv("CHIRP_MAX_LENGTH"); maxLength != "" {
+		parsed, err := strconv.Atoi(maxLength)
+		if err != nil || parsed < 1 {
+			log.Fatal("CHIRP_MAX_LENGTH must be a positive integer")
+		}
+		chirpValidation.MaxLength = parsed
+	}
+	if urlLength := os.Getenv("CHIRP_URL_LENGTH"); urlLength != "" {
+		parsed, err := strconv.Atoi(urlLength)
+		if err != nil || parsed < 0 {
+			log.Fatal("CHIRP_URL_LENGTH must be a non-negative integer")
+		}
+		chirpValidation.URLLength = parsed
+	}
+	linkPreviewTimeout := linkpreview.DefaultTimeout
+	if timeout := os.Getenv("LINK_PREVIEW_TIMEOUT"); timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatalf("LINK_PREVIEW_TIMEOUT must be a duration: %s", err)
+		}
+		linkPreviewTimeout = parsed
+	}
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "media"
+	}
+	// Uploads must only be reachable through the API, which checks who can
+	// see them.
+	if within, err := pathWithin(mediaDir, filepathRoot); err != nil || within {
+		log.Fatalf("MEDIA_DIR must not be inside %s", filepathRoot)
+	}
+	mediaStore, err := storage.NewLocal(mediaDir)
+	if err != nil {
+		log.Fatalf("MEDIA_DIR: %s", err)
+	}
 	const port = "8080"
 	mux := http.NewServeMux()
 	apiCfg := &apiConfig{}
@@ -39,23 +125,86 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	apiCfg.db = db
 	apiCfg.dbQueries = database.New(db)
 	apiCfg.platform = platform
 	apiCfg.jwtSecret = JWTSecret
 	apiCfg.polkaKey = polkaKey
+	apiCfg.adminKey = adminKey
+	apiCfg.chirpEditWindow = chirpEditWindow
+	apiCfg.chirpValidation = chirpValidation
+	apiCfg.trashRetention = trashRetention
+	apiCfg.media = mediaStore
+	apiCfg.linkPreviewer = &linkpreview.Fetcher{Client: linkpreview.NewClient(linkPreviewTimeout)}
+	apiCfg.linkPreviewJobs = make(chan linkPreviewJob, linkPreviewQueueSize)
+	apiCfg.chirpStream = stream.NewHub(chirpStreamBuffer)
 	// /api/healthz
 	mux.HandleFunc("GET /api/healthz", healthzHandler)
 	// /api/admin (do not document)
 	mux.HandleFunc("GET /admin/metrics", apiCfg.metricsHandler)
 	mux.HandleFunc("POST /admin/reset", apiCfg.resetHandler)
+	mux.HandleFunc("POST /admin/hashtags/backfill", apiCfg.backfillHashtagsHandler)
+	mux.HandleFunc("POST /admin/timelines/rebuild", apiCfg.rebuildTimelinesHandler)
+	mux.HandleFunc("GET /admin/filters", apiCfg.getWordFiltersHandler)
+	mux.HandleFunc("POST /admin/filters", apiCfg.createWordFilterHandler)
+	mux.HandleFunc("PUT /admin/filters/{filterID}", apiCfg.updateWordFilterHandler)
+	mux.HandleFunc("DELETE /admin/filters/{filterID}", apiCfg.deleteWordFilterHandler)
+	mux.HandleFunc("GET /admin/flagged-chirps", apiCfg.getFlaggedChirpsHandler)
+	mux.HandleFunc("GET /admin/chirps/{chirpID}", apiCfg.adminGetChirpHandler)
+	mux.HandleFunc("GET /admin/users/{userID}/state", apiCfg.getAccountStateHandler)
+	mux.HandleFunc("PUT /admin/users/{userID}/state", apiCfg.setAccountStateHandler)
+	mux.HandleFunc("GET /admin/moderation/reports", apiCfg.getReportsHandler)
+	mux.HandleFunc("GET /admin/moderation/reports/{reportID}", apiCfg.getReportHandler)
+	mux.HandleFunc("POST /admin/moderation/reports/{reportID}/claim", apiCfg.claimReportHandler)
+	mux.HandleFunc("POST /admin/moderation/reports/{reportID}/resolve", apiCfg.resolveReportHandler)
+	mux.HandleFunc("POST /admin/moderation/reports/{reportID}/notes", apiCfg.annotateReportHandler)
 	// /api/chirps
 	mux.HandleFunc("GET /api/chirps", apiCfg.getChirpsHandler)
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.searchChirpsHandler)
+	mux.HandleFunc("GET /api/chirps/stream", apiCfg.streamChirpsHandler)
+	mux.HandleFunc("GET /api/timeline", apiCfg.getTimelineHandler)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.getChirpsByIdHandler)
 	mux.HandleFunc("POST /api/chirps", apiCfg.createChirpHandler)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.updateChirpHandler)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.deleteChirpHandler)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.getChirpRevisionsHandler)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/replies", apiCfg.getChirpRepliesHandler)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.getChirpThreadHandler)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/likes", apiCfg.getChirpLikesHandler)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/likes", apiCfg.likeChirpHandler)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", apiCfg.unlikeChirpHandler)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.rechirpHandler)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.undoRechirpHandler)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/quote", apiCfg.quoteChirpHandler)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/restore", apiCfg.restoreChirpHandler)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", apiCfg.votePollHandler)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/reports", apiCfg.reportChirpHandler)
+	// /api/hashtags
+	mux.HandleFunc("GET /api/hashtags/trending", apiCfg.getTrendingHashtagsHandler)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.getHashtagChirpsHandler)
+	// /api/media
+	mux.HandleFunc("POST /api/media", apiCfg.uploadMediaHandler)
+	mux.HandleFunc("GET /api/media/{mediaID}", apiCfg.getMediaHandler)
+	mux.HandleFunc("GET /api/media/{mediaID}/thumbnail", apiCfg.getMediaThumbnailHandler)
 	// /api/users
 	mux.HandleFunc("POST /api/users", apiCfg.createUserHandler)
 	mux.HandleFunc("PUT /api/users", apiCfg.usersHandler)
+	mux.HandleFunc("PATCH /api/users/me", apiCfg.updateProfileHandler)
+	mux.HandleFunc("GET /api/users/{user}", apiCfg.getProfileHandler)
+	mux.HandleFunc("GET /api/users/me/trash", apiCfg.getTrashHandler)
+	mux.HandleFunc("GET /api/users/me/export", apiCfg.exportArchiveHandler)
+	mux.HandleFunc("POST /api/users/me/import", apiCfg.importArchiveHandler)
+	mux.HandleFunc("GET /api/users/me/scheduled", apiCfg.getScheduledChirpsHandler)
+	mux.HandleFunc("DELETE /api/users/me/scheduled/{chirpID}", apiCfg.cancelScheduledChirpHandler)
+	mux.HandleFunc("GET /api/users/me/drafts", apiCfg.getDraftsHandler)
+	mux.HandleFunc("POST /api/users/me/drafts", apiCfg.createDraftHandler)
+	mux.HandleFunc("PUT /api/users/me/drafts/{draftID}", apiCfg.updateDraftHandler)
+	mux.HandleFunc("DELETE /api/users/me/drafts/{draftID}", apiCfg.deleteDraftHandler)
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.followUserHandler)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.unfollowUserHandler)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.getFollowersHandler)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.getFollowingHandler)
+	mux.HandleFunc("POST /api/drafts/{draftID}/publish", apiCfg.publishDraftHandler)
 
 	mux.HandleFunc("POST /api/login", apiCfg.loginHandler)
 
@@ -65,27 +214,71 @@ func main() {
 	// (do not document)
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.userUpgradeHandler)
 
-	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(".")))))
+	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filepathRoot)))))
 	server := &http.Server{
-		Handler: mux,
+		Handler: apiCfg.middlewareRejectSuspended(mux),
 		Addr:    ":" + port,
 	}
+	go apiCfg.purgeTrashLoop(context.Background(), trashPurgeInterval)
+	go apiCfg.publishScheduledLoop(context.Background(), scheduledPublishInterval)
+	go apiCfg.cleanUpAttachmentsLoop(context.Background(), attachmentCleanupInterval)
+	go apiCfg.reapExpiredChirpsLoop(context.Background(), chirpReapInterval)
+	go apiCfg.followChirpEvents(context.Background(), dbURL)
+	go apiCfg.followWordFilterChanges(context.Background(), dbURL)
+	for range linkPreviewWorkers {
+		go apiCfg.linkPreviewWorker(context.Background())
+	}
+
 	log.Printf("Serving on port: %s\n", port)
 	log.Fatal(server.ListenAndServe())
 }
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	dbQueries      *database.Queries
-	platform       string
-	jwtSecret      string
-	polkaKey       string
+	fileserverHits  atomic.Int32
+	db              *sql.DB
+	dbQueries       *database.Queries
+	platform        string
+	jwtSecret       string
+	polkaKey        string
+	adminKey        string
+	chirpEditWindow time.Duration
+	chirpValidation validation.Config
+	trashRetention  time.Duration
+	wordFilters     wordFilterCache
+	media           storage.Store
+	linkPreviewer   *linkpreview.Fetcher
+	linkPreviewJobs chan linkPreviewJob
+	chirpStream     *stream.Hub
 }
 
 type User struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Email     string    `json:"email"`
-	ChirpyRed bool      `json:"is_chirpy_red"`
+	ID             uuid.UUID `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Email          string    `json:"email"`
+	ChirpyRed      bool      `json:"is_chirpy_red"`
+	FollowerCount  int32     `json:"follower_count"`
+	FollowingCount int32     `json:"following_count"`
+	Handle         *string   `json:"handle"`
+	DisplayName    string    `json:"display_name"`
+	Bio            string    `json:"bio"`
+	Website        string    `json:"website"`
+	AvatarURL      string    `json:"avatar_url"`
+}
+
+// pathWithin reports whether path is root or somewhere below it.
+func pathWithin(path, root string) (bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil {
+		return false, err
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))), nil
 }
@@ -0,0 +1,543 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	maxReportDetailsLength = 1000
+	maxModerationNote      = 2000
+	hiddenChirpNotice      = "This chirp was hidden by a moderator. Only you can see it."
+)
+
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate":           true,
+	"violence":       true,
+	"sexual":         true,
+	"misinformation": true,
+	"other":          true,
+}
+
+// Report statuses. Reports are open until a moderator claims them and
+// claimed until one resolves them.
+const (
+	reportOpen     = "open"
+	reportClaimed  = "claimed"
+	reportResolved = "resolved"
+)
+
+// Resolutions, which double as moderation actions along with claim and note.
+const (
+	resolutionDismiss = "dismiss"
+	resolutionHide    = "hide"
+	resolutionRemove  = "remove"
+	resolutionSuspend = "suspend"
+)
+
+type Report struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ChirpID     *uuid.UUID `json:"chirp_id"`
+	ChirpUserID uuid.UUID  `json:"chirp_user_id"`
+	ChirpBody   string     `json:"chirp_body"`
+	ReporterID  uuid.UUID  `json:"reporter_id"`
+	Reason      string     `json:"reason"`
+	Details     string     `json:"details"`
+	Status      string     `json:"status"`
+	ClaimedBy   *string    `json:"claimed_by,omitempty"`
+	ClaimedAt   *time.Time `json:"claimed_at,omitempty"`
+	Resolution  *string    `json:"resolution,omitempty"`
+	ResolvedBy  *string    `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ModerationAction struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Moderator string     `json:"moderator"`
+	Action    string     `json:"action"`
+	ChirpID   *uuid.UUID `json:"chirp_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Note      string     `json:"note,omitempty"`
+}
+
+// reportChirpHandler lets a user flag a chirp they can see for moderators.
+// The chirp's body is kept with the report, so editing or deleting the
+// chirp afterwards doesn't change what moderators review.
+func (cfg *apiConfig) reportChirpHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if !reportReasons[params.Reason] {
+		respondWithError(w, http.StatusBadRequest, "Reason must be spam, harassment, hate, violence, sexual, misinformation or other", nil)
+		return
+	}
+	if len(params.Details) > maxReportDetailsLength {
+		respondWithError(w, http.StatusBadRequest, "Details are too long", nil)
+		return
+	}
+
+	chirp, err := cfg.viewableChirp(r, cfg.dbQueries, id)
+	if err == nil && chirp.RechirpOfID.Valid {
+		// A plain rechirp has nothing of its own to report.
+		chirp, err = cfg.viewableChirp(r, cfg.dbQueries, chirp.RechirpOfID.UUID)
+	}
+	if err == nil && chirp.TombstonedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if chirp.UserID == validatedUserID {
+		respondWithError(w, http.StatusBadRequest, "You can't report your own chirp", nil)
+		return
+	}
+
+	report, err := cfg.dbQueries.CreateReport(r.Context(), database.CreateReportParams{
+		ChirpID:     uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		ChirpUserID: chirp.UserID,
+		ChirpBody:   chirp.Body,
+		ReporterID:  validatedUserID,
+		Reason:      params.Reason,
+		Details:     params.Details,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusConflict, "You've already reported this chirp", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, reportFromDB(report))
+}
+
+// getReportsHandler is the moderation queue: open and claimed reports,
+// oldest first. status picks one status instead, resolved included.
+func (cfg *apiConfig) getReportsHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	statuses := []string{reportOpen, reportClaimed}
+	switch status := r.URL.Query().Get("status"); status {
+	case "":
+	case reportOpen, reportClaimed, reportResolved:
+		statuses = []string{status}
+	default:
+		respondWithError(w, http.StatusBadRequest, "Status must be open, claimed or resolved", nil)
+		return
+	}
+
+	cursorCreatedAt, cursorID := page.cursorArgs()
+	rows, err := cfg.dbQueries.GetReports(r.Context(), database.GetReportsParams{
+		Statuses:        statuses,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve reports", err)
+		return
+	}
+
+	rows, next, _ := paginate(rows, page, func(row database.Report) pageCursor {
+		return pageCursor{CreatedAt: row.CreatedAt, ID: row.ID}
+	})
+	reports := []Report{}
+	for _, row := range rows {
+		reports = append(reports, reportFromDB(row))
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, reports)
+}
+
+// getReportHandler shows a report with the chirp as it is now, if it's still
+// around, and everything moderators have done about it.
+func (cfg *apiConfig) getReportHandler(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Report
+		Chirp   *Chirp             `json:"chirp"`
+		Actions []ModerationAction `json:"actions"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	id, ok := reportIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	report, err := cfg.dbQueries.GetReport(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Report not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve report", err)
+		return
+	}
+	resp := response{Report: reportFromDB(report), Actions: []ModerationAction{}}
+	if report.ChirpID.Valid {
+		chirp, err := cfg.dbQueries.ChirpsByIdIncludingDeleted(r.Context(), report.ChirpID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp", err)
+			return
+		}
+		if err == nil {
+			c := fullChirpFromDB(chirp)
+			resp.Chirp = &c
+		}
+	}
+	actions, err := cfg.dbQueries.GetModerationActions(r.Context(), report.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve moderation actions", err)
+		return
+	}
+	for _, action := range actions {
+		resp.Actions = append(resp.Actions, moderationActionFromDB(action))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// claimReportHandler assigns a report to a moderator so two of them don't
+// work on it at once. Claiming a report someone else holds is refused.
+func (cfg *apiConfig) claimReportHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Moderator string `json:"moderator"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	id, ok := reportIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if params.Moderator == "" {
+		respondWithError(w, http.StatusBadRequest, "Moderator is required", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	report, ok := lockReport(w, r, qtx, id, params.Moderator)
+	if !ok {
+		return
+	}
+	report, err = qtx.ClaimReport(r.Context(), database.ClaimReportParams{
+		Moderator: sql.NullString{String: params.Moderator, Valid: true},
+		ID:        report.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't claim report", err)
+		return
+	}
+	if err := recordModerationAction(r.Context(), qtx, report, params.Moderator, "claim", ""); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't claim report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reportFromDB(report))
+}
+
+// resolveReportHandler closes a report, acting on it first:
+//   - dismiss: nothing was wrong.
+//   - hide: only the author can see the chirp from now on.
+//   - remove: the chirp is deleted for good, or tombstoned if it has replies.
+//   - suspend: the author is suspended until a moderator lifts it.
+func (cfg *apiConfig) resolveReportHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Moderator  string `json:"moderator"`
+		Resolution string `json:"resolution"`
+		Note       string `json:"note"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	id, ok := reportIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if params.Moderator == "" {
+		respondWithError(w, http.StatusBadRequest, "Moderator is required", nil)
+		return
+	}
+	switch params.Resolution {
+	case resolutionDismiss, resolutionHide, resolutionRemove, resolutionSuspend:
+	default:
+		respondWithError(w, http.StatusBadRequest, "Resolution must be dismiss, hide, remove or suspend", nil)
+		return
+	}
+	if len(params.Note) > maxModerationNote {
+		respondWithError(w, http.StatusBadRequest, "Note is too long", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	report, ok := lockReport(w, r, qtx, id, params.Moderator)
+	if !ok {
+		return
+	}
+	switch params.Resolution {
+	case resolutionHide:
+		if report.ChirpID.Valid {
+			err = qtx.HideChirp(r.Context(), report.ChirpID.UUID)
+		}
+	case resolutionRemove:
+		if report.ChirpID.Valid {
+			err = destroyChirp(r.Context(), qtx, report.ChirpID.UUID)
+			if errors.Is(err, sql.ErrNoRows) {
+				err = nil
+			}
+		}
+	case resolutionSuspend:
+		reason := params.Note
+		if reason == "" {
+			reason = "Reported for " + report.Reason
+		}
+		_, err = setAccountState(r.Context(), qtx, report.ChirpUserID, accountSuspended, sql.NullTime{}, reason, params.Moderator)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve report", err)
+		return
+	}
+	report, err = qtx.ResolveReport(r.Context(), database.ResolveReportParams{
+		Resolution: sql.NullString{String: params.Resolution, Valid: true},
+		Moderator:  sql.NullString{String: params.Moderator, Valid: true},
+		ID:         report.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve report", err)
+		return
+	}
+	if err := recordModerationAction(r.Context(), qtx, report, params.Moderator, params.Resolution, params.Note); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reportFromDB(report))
+}
+
+// annotateReportHandler adds a moderator's note to a report's history. Notes
+// can be added at any time, resolved reports included.
+func (cfg *apiConfig) annotateReportHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Moderator string `json:"moderator"`
+		Note      string `json:"note"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	id, ok := reportIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if params.Moderator == "" {
+		respondWithError(w, http.StatusBadRequest, "Moderator is required", nil)
+		return
+	}
+	if params.Note == "" {
+		respondWithError(w, http.StatusBadRequest, "Note is required", nil)
+		return
+	}
+	if len(params.Note) > maxModerationNote {
+		respondWithError(w, http.StatusBadRequest, "Note is too long", nil)
+		return
+	}
+
+	report, err := cfg.dbQueries.GetReport(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Report not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve report", err)
+		return
+	}
+	action, err := cfg.dbQueries.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		ReportID:  report.ID,
+		Moderator: params.Moderator,
+		Action:    "note",
+		ChirpID:   report.ChirpID,
+		UserID:    report.ChirpUserID,
+		Note:      params.Note,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, moderationActionFromDB(action))
+}
+
+func reportIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid report ID format", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// lockReport loads a report for moderator to act on, refusing reports that
+// are already resolved or claimed by someone else.
+func lockReport(w http.ResponseWriter, r *http.Request, q *database.Queries, id uuid.UUID, moderator string) (database.Report, bool) {
+	report, err := q.GetReportForUpdate(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Report not found", nil)
+			return database.Report{}, false
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve report", err)
+		return database.Report{}, false
+	}
+	switch {
+	case report.Status == reportResolved:
+		respondWithError(w, http.StatusConflict, "Report is already resolved", nil)
+		return database.Report{}, false
+	case report.Status == reportClaimed && report.ClaimedBy.String != moderator:
+		respondWithError(w, http.StatusConflict, "Report is claimed by "+report.ClaimedBy.String, nil)
+		return database.Report{}, false
+	}
+	return report, true
+}
+
+func recordModerationAction(ctx context.Context, q *database.Queries, report database.Report, moderator, action, note string) error {
+	_, err := q.CreateModerationAction(ctx, database.CreateModerationActionParams{
+		ReportID:  report.ID,
+		Moderator: moderator,
+		Action:    action,
+		ChirpID:   report.ChirpID,
+		UserID:    report.ChirpUserID,
+		Note:      note,
+	})
+	return err
+}
+
+func reportFromDB(report database.Report) Report {
+	return Report{
+		ID:          report.ID,
+		CreatedAt:   report.CreatedAt,
+		UpdatedAt:   report.UpdatedAt,
+		ChirpID:     nullUUIDPtr(report.ChirpID),
+		ChirpUserID: report.ChirpUserID,
+		ChirpBody:   report.ChirpBody,
+		ReporterID:  report.ReporterID,
+		Reason:      report.Reason,
+		Details:     report.Details,
+		Status:      report.Status,
+		ClaimedBy:   nullStringPtr(report.ClaimedBy),
+		ClaimedAt:   nullTimePtr(report.ClaimedAt),
+		Resolution:  nullStringPtr(report.Resolution),
+		ResolvedBy:  nullStringPtr(report.ResolvedBy),
+		ResolvedAt:  nullTimePtr(report.ResolvedAt),
+	}
+}
+
+func moderationActionFromDB(action database.ModerationAction) ModerationAction {
+	return ModerationAction{
+		ID:        action.ID,
+		CreatedAt: action.CreatedAt,
+		Moderator: action.Moderator,
+		Action:    action.Action,
+		ChirpID:   nullUUIDPtr(action.ChirpID),
+		UserID:    action.UserID,
+		Note:      action.Note,
+	}
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// pageCursor identifies a row by the (created_at, id) pair that keyset
+// pagination orders on.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c pageCursor) encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePageCursor(token string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	return pageCursor{CreatedAt: t, ID: parsedID}, nil
+}
+
+type pageParams struct {
+	limit  int32
+	cursor *pageCursor
+	// before is set when the cursor bounds the page from the end rather
+	// than the start, i.e. the client is paging backwards.
+	before bool
+}
+
+// parsePageParams reads limit, cursor, after and before from the query
+// string. cursor is an alias for after.
+func parsePageParams(query url.Values) (pageParams, error) {
+	limit, err := parseLimit(query)
+	if err != nil {
+		return pageParams{}, err
+	}
+	params := pageParams{limit: limit}
+
+	after := query.Get("after")
+	if after == "" {
+		after = query.Get("cursor")
+	}
+	before := query.Get("before")
+	if after != "" && before != "" {
+		return pageParams{}, errors.New("Only one of after and before may be set")
+	}
+	token := after
+	if before != "" {
+		token = before
+		params.before = true
+	}
+	if token != "" {
+		cursor, err := decodePageCursor(token)
+		if err != nil {
+			return pageParams{}, errors.New("Invalid cursor")
+		}
+		params.cursor = &cursor
+	}
+	return params, nil
+}
+
+// parseLimit reads the page size from the query string, capping it at
+// maxPageLimit.
+func parseLimit(query url.Values) (int32, error) {
+	limitString := query.Get("limit")
+	if limitString == "" {
+		return defaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(limitString)
+	if err != nil || limit < 1 {
+		return 0, errors.New("Invalid limit")
+	}
+	return int32(min(limit, maxPageLimit)), nil
+}
+
+func (p pageParams) cursorArgs() (sql.NullTime, uuid.NullUUID) {
+	if p.cursor == nil {
+		return sql.NullTime{}, uuid.NullUUID{}
+	}
+	return sql.NullTime{Time: p.cursor.CreatedAt, Valid: true},
+		uuid.NullUUID{UUID: p.cursor.ID, Valid: true}
+}
+
+// fetchLimit is one more than the page size so callers can tell whether
+// another page exists.
+func (p pageParams) fetchLimit() int32 {
+	return p.limit + 1
+}
+
+// paginate trims rows fetched with fetchLimit down to a page, restores the
+// requested order when paging backwards, and returns the cursors of the
+// neighbouring pages ("" when there is none).
+func paginate[T any](items []T, params pageParams, cursorOf func(T) pageCursor) (page []T, next, prev string) {
+	hasMore := len(items) > int(params.limit)
+	if hasMore {
+		items = items[:params.limit]
+	}
+	if params.before {
+		slices.Reverse(items)
+	}
+	if len(items) == 0 {
+		return items, "", ""
+	}
+	first := cursorOf(items[0]).encode()
+	last := cursorOf(items[len(items)-1]).encode()
+	if params.before {
+		if hasMore {
+			prev = first
+		}
+		return items, last, prev
+	}
+	if hasMore {
+		next = last
+	}
+	if params.cursor != nil {
+		prev = first
+	}
+	return items, next, prev
+}
+
+// setPageLinks advertises the neighbouring pages in a Link header and the
+// X-Next-Cursor/X-Prev-Cursor headers. X-Next-Cursor is the next_cursor
+// clients page with; it's a header rather than a body field so list bodies
+// stay the plain JSON arrays they always were. It must run before the body
+// is written.
+func setPageLinks(w http.ResponseWriter, r *http.Request, next, prev string) {
+	links := []string{}
+	if next != "" {
+		w.Header().Set("X-Next-Cursor", next)
+		links = append(links, pageLink(r, "after", next, "next"))
+	}
+	if prev != "" {
+		w.Header().Set("X-Prev-Cursor", prev)
+		links = append(links, pageLink(r, "before", prev, "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageLink(r *http.Request, param, cursor, rel string) string {
+	query := r.URL.Query()
+	query.Del("cursor")
+	query.Del("after")
+	query.Del("before")
+	query.Set(param, cursor)
+	u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
+
+// parseForwardPageParams is parsePageParams for listings that can only be
+// walked from the start.
+func parseForwardPageParams(query url.Values) (pageParams, error) {
+	params, err := parsePageParams(query)
+	if err != nil {
+		return pageParams{}, err
+	}
+	if params.before {
+		return pageParams{}, errors.New("before is not supported for this listing")
+	}
+	return params, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPageCursorRoundTrip(t *testing.T) {
+	want := pageCursor{
+		CreatedAt: time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC),
+		ID:        uuid.MustParse("0b6a6c1e-6f5b-4b8e-9a43-4a4c3f0d2f11"),
+	}
+	got, err := decodePageCursor(want.encode())
+	if err != nil {
+		t.Fatalf("decodePageCursor: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodePageCursorRejectsMalformed(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "!!!"},
+		{name: "no separator", token: encode("2024-03-01T12:30:00Z")},
+		{name: "bad time", token: encode("yesterday,0b6a6c1e-6f5b-4b8e-9a43-4a4c3f0d2f11")},
+		{name: "bad id", token: encode("2024-03-01T12:30:00Z,nope")},
+		{name: "empty", token: ""},
+	}
+	for _, tt := range tests {
+		if _, err := decodePageCursor(tt.token); err == nil {
+			t.Errorf("%s: decodePageCursor(%q) succeeded", tt.name, tt.token)
+		}
+	}
+}
+
+func TestParsePageParams(t *testing.T) {
+	cursor := testCursor(1).encode()
+	tests := []struct {
+		name       string
+		query      string
+		wantLimit  int32
+		wantCursor bool
+		wantBefore bool
+		wantErr    bool
+	}{
+		{name: "defaults", query: "", wantLimit: defaultPageLimit},
+		{name: "limit", query: "limit=10", wantLimit: 10},
+		{name: "limit capped", query: "limit=1000", wantLimit: maxPageLimit},
+		{name: "zero limit", query: "limit=0", wantErr: true},
+		{name: "negative limit", query: "limit=-1", wantErr: true},
+		{name: "non-numeric limit", query: "limit=ten", wantErr: true},
+		{name: "after", query: "after=" + cursor, wantLimit: defaultPageLimit, wantCursor: true},
+		{name: "cursor alias", query: "cursor=" + cursor, wantLimit: defaultPageLimit, wantCursor: true},
+		{name: "before", query: "before=" + cursor, wantLimit: defaultPageLimit, wantCursor: true, wantBefore: true},
+		{name: "after and before", query: "after=" + cursor + "&before=" + cursor, wantErr: true},
+		{name: "invalid cursor", query: "after=garbage", wantErr: true},
+	}
+	for _, tt := range tests {
+		query, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("%s: ParseQuery: %v", tt.name, err)
+		}
+		got, err := parsePageParams(query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got.limit != tt.wantLimit || (got.cursor != nil) != tt.wantCursor || got.before != tt.wantBefore {
+			t.Errorf("%s: got limit %d, cursor %v, before %v", tt.name, got.limit, got.cursor != nil, got.before)
+		}
+	}
+}
+
+func TestParseForwardPageParamsRejectsBefore(t *testing.T) {
+	query := url.Values{"before": {testCursor(1).encode()}}
+	if _, err := parseForwardPageParams(query); err == nil {
+		t.Error("parseForwardPageParams accepted before")
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	c := func(i int) string { return testCursor(i).encode() }
+	tests := []struct {
+		name string
+		// items are as the query returns them: ascending from the cursor
+		// going forwards, descending from it going backwards.
+		items    []int
+		params   pageParams
+		wantPage []int
+		wantNext string
+		wantPrev string
+	}{
+		{
+			name:     "empty first page",
+			items:    []int{},
+			params:   pageParams{limit: 3},
+			wantPage: []int{},
+		},
+		{
+			name:     "short first page",
+			items:    []int{1, 2},
+			params:   pageParams{limit: 3},
+			wantPage: []int{1, 2},
+		},
+		{
+			name:     "exact first page",
+			items:    []int{1, 2, 3},
+			params:   pageParams{limit: 3},
+			wantPage: []int{1, 2, 3},
+		},
+		{
+			name:     "first page with more",
+			items:    []int{1, 2, 3, 4},
+			params:   pageParams{limit: 3},
+			wantPage: []int{1, 2, 3},
+			wantNext: c(3),
+		},
+		{
+			name:     "middle page",
+			items:    []int{4, 5, 6, 7},
+			params:   pageParams{limit: 3, cursor: ptr(testCursor(3))},
+			wantPage: []int{4, 5, 6},
+			wantNext: c(6),
+			wantPrev: c(4),
+		},
+		{
+			name:     "last page",
+			items:    []int{7, 8},
+			params:   pageParams{limit: 3, cursor: ptr(testCursor(6))},
+			wantPage: []int{7, 8},
+			wantPrev: c(7),
+		},
+		{
+			name:     "empty page after cursor",
+			items:    []int{},
+			params:   pageParams{limit: 3, cursor: ptr(testCursor(8))},
+			wantPage: []int{},
+		},
+		{
+			name:     "before with more",
+			items:    []int{6, 5, 4, 3},
+			params:   pageParams{limit: 3, cursor: ptr(testCursor(7)), before: true},
+			wantPage: []int{4, 5, 6},
+			wantNext: c(6),
+			wantPrev: c(4),
+		},
+		{
+			name:     "before reaching the first page exactly",
+			items:    []int{3, 2, 1},
+			params:   pageParams{limit: 3, cursor: ptr(testCursor(4)), before: true},
+			wantPage: []int{1, 2, 3},
+			wantNext: c(3),
+		},
+		{
+			name:     "before with a short first page",
+			items:    []int{2, 1},
+			params:   pageParams{limit: 3, cursor: ptr(testCursor(3)), before: true},
+			wantPage: []int{1, 2},
+			wantNext: c(2),
+		},
+		{
+			name:     "empty page before cursor",
+			items:    []int{},
+			params:   pageParams{limit: 3, cursor: ptr(testCursor(1)), before: true},
+			wantPage: []int{},
+		},
+	}
+	for _, tt := range tests {
+		page, next, prev := paginate(slices.Clone(tt.items), tt.params, testCursor)
+		if !slices.Equal(page, tt.wantPage) {
+			t.Errorf("%s: page = %v, want %v", tt.name, page, tt.wantPage)
+		}
+		if next != tt.wantNext {
+			t.Errorf("%s: next = %q, want %q", tt.name, next, tt.wantNext)
+		}
+		if prev != tt.wantPrev {
+			t.Errorf("%s: prev = %q, want %q", tt.name, prev, tt.wantPrev)
+		}
+	}
+}
+
+func TestSetPageLinks(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/chirps?author_id=abc&limit=2&cursor=old", nil)
+	w := httptest.NewRecorder()
+	setPageLinks(w, r, "n", "p")
+
+	if got := w.Header().Get("X-Next-Cursor"); got != "n" {
+		t.Errorf("X-Next-Cursor = %q", got)
+	}
+	if got := w.Header().Get("X-Prev-Cursor"); got != "p" {
+		t.Errorf("X-Prev-Cursor = %q", got)
+	}
+	want := `</api/chirps?after=n&author_id=abc&limit=2>; rel="next", </api/chirps?author_id=abc&before=p&limit=2>; rel="prev"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+}
+
+func TestSetPageLinksWithoutNeighbours(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/chirps", nil)
+	w := httptest.NewRecorder()
+	setPageLinks(w, r, "", "")
+	for _, header := range []string{"Link", "X-Next-Cursor", "X-Prev-Cursor"} {
+		if got := w.Header().Get(header); got != "" {
+			t.Errorf("%s = %q, want none", header, got)
+		}
+	}
+}
+
+// testCursor gives each test item a distinct, increasing position.
+func testCursor(i int) pageCursor {
+	return pageCursor{
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+		ID:        uuid.NewSHA1(uuid.Nil, []byte{byte(i)}),
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 50
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// Poll is a chirp's poll as seen by one viewer. Tallies are live while the
+// poll is open and fixed once it closes. Who voted for what is never shown;
+// MyVote is only the viewer's own choice.
+type Poll struct {
+	Options    []PollOption `json:"options"`
+	TotalVotes int32        `json:"total_votes"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	Closed     bool         `json:"closed"`
+	MyVote     *int32       `json:"my_vote,omitempty"`
+}
+
+type PollOption struct {
+	Position int32  `json:"position"`
+	Text     string `json:"text"`
+	Votes    int32  `json:"votes"`
+}
+
+type pollParameters struct {
+	Options   []string  `json:"options"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// checkPoll validates a poll sent with a new chirp and tidies its options.
+func checkPoll(poll *pollParameters, now time.Time) error {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return errors.New("Polls need between 2 and 4 options")
+	}
+	seen := make(map[string]bool, len(poll.Options))
+	for i, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return errors.New("Poll options can't be empty")
+		}
+		if validation.Count(option, 0) > maxPollOptionLength {
+			return errors.New("Poll option is too long")
+		}
+		if seen[strings.ToLower(option)] {
+			return errors.New("Poll options must be different")
+		}
+		seen[strings.ToLower(option)] = true
+		poll.Options[i] = option
+	}
+	if poll.ExpiresAt.Before(now.Add(minPollDuration)) {
+		return errors.New("Polls must stay open for at least 5 minutes")
+	}
+	if poll.ExpiresAt.After(now.Add(maxPollDuration)) {
+		return errors.New("Polls can stay open for at most 7 days")
+	}
+	return nil
+}
+
+func createPoll(ctx context.Context, q *database.Queries, chirpID uuid.UUID, poll pollParameters) error {
+	err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:   chirpID,
+		ExpiresAt: poll.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	for i, option := range poll.Options {
+		err := q.CreatePollOption(ctx, database.CreatePollOptionParams{
+			ChirpID:  chirpID,
+			Position: int32(i),
+			Text:     option,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) votePollHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Option *int32 `json:"option"`
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	if params.Option == nil {
+		respondWithError(w, http.StatusBadRequest, "Option is required", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := cfg.viewableChirp(r, qtx, id)
+	if err == nil && chirp.RechirpOfID.Valid {
+		// A plain rechirp shows its original's poll.
+		chirp, err = cfg.viewableChirp(r, qtx, chirp.RechirpOfID.UUID)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	polls, err := loadPolls(r.Context(), qtx, validatedUserID, []uuid.UUID{chirp.ID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve poll", err)
+		return
+	}
+	poll, ok := polls[chirp.ID]
+	if !ok || chirp.TombstonedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Chirp has no poll", nil)
+		return
+	}
+	if poll.Closed {
+		respondWithError(w, http.StatusConflict, "Poll has closed", nil)
+		return
+	}
+	if *params.Option < 0 || int(*params.Option) >= len(poll.Options) {
+		respondWithError(w, http.StatusBadRequest, "Invalid poll option", nil)
+		return
+	}
+
+	// The insert checks the poll is still open too, so a vote racing the
+	// close stays out of the final tally.
+	cast, err := qtx.CastPollVote(r.Context(), database.CastPollVoteParams{
+		ChirpID:  chirp.ID,
+		UserID:   validatedUserID,
+		Position: *params.Option,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record vote", err)
+		return
+	}
+	if cast == 0 {
+		// Either the vote is already in or the poll closed since it was
+		// loaded.
+		votes, err := qtx.GetPollVotesByUser(r.Context(), database.GetPollVotesByUserParams{
+			UserID:   validatedUserID,
+			ChirpIds: []uuid.UUID{chirp.ID},
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't record vote", err)
+			return
+		}
+		if len(votes) == 0 {
+			respondWithError(w, http.StatusConflict, "Poll has closed", nil)
+			return
+		}
+		respondWithError(w, http.StatusConflict, "You have already voted in this poll", nil)
+		return
+	}
+	err = qtx.IncrementPollOptionVotes(r.Context(), database.IncrementPollOptionVotesParams{
+		ChirpID:  chirp.ID,
+		Position: *params.Option,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record vote", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record vote", err)
+		return
+	}
+
+	poll.Options[*params.Option].Votes++
+	poll.TotalVotes++
+	poll.MyVote = params.Option
+	respondWithJSON(w, http.StatusCreated, poll)
+}
+
+// loadPolls builds the polls on the given chirps, keyed by chirp ID. When
+// viewerID isn't uuid.Nil each poll carries that user's vote.
+func loadPolls(ctx context.Context, q *database.Queries, viewerID uuid.UUID, chirpIDs []uuid.UUID) (map[uuid.UUID]Poll, error) {
+	dbPolls, err := q.GetPolls(ctx, chirpIDs)
+	if err != nil || len(dbPolls) == 0 {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(dbPolls))
+	for _, poll := range dbPolls {
+		ids = append(ids, poll.ChirpID)
+	}
+	options, err := q.GetPollOptions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	votes := []database.PollVote{}
+	if viewerID != uuid.Nil {
+		votes, err = q.GetPollVotesByUser(ctx, database.GetPollVotesByUserParams{
+			UserID:   viewerID,
+			ChirpIds: ids,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	polls := make(map[uuid.UUID]Poll, len(dbPolls))
+	for _, dbPoll := range dbPolls {
+		polls[dbPoll.ChirpID] = Poll{
+			Options:   []PollOption{},
+			ExpiresAt: dbPoll.ExpiresAt,
+			Closed:    !now.Before(dbPoll.ExpiresAt),
+		}
+	}
+	for _, option := range options {
+		poll := polls[option.ChirpID]
+		poll.Options = append(poll.Options, PollOption{
+			Position: option.Position,
+			Text:     option.Text,
+			Votes:    option.VoteCount,
+		})
+		poll.TotalVotes += option.VoteCount
+		polls[option.ChirpID] = poll
+	}
+	for _, vote := range votes {
+		poll := polls[vote.ChirpID]
+		position := vote.Position
+		poll.MyVote = &position
+		polls[vote.ChirpID] = poll
+	}
+	return polls, nil
+}
+
+// embedPolls fills in the polls on chirps and on the chirps they rechirp or
+// quote.
+func (cfg *apiConfig) embedPolls(ctx context.Context, viewerID uuid.UUID, chirps []Chirp) error {
+	targets := visibleChirps(chirps)
+	if len(targets) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(targets))
+	for _, chirp := range targets {
+		ids = append(ids, chirp.ID)
+	}
+	polls, err := loadPolls(ctx, cfg.dbQueries, viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for _, chirp := range targets {
+		if poll, ok := polls[chirp.ID]; ok {
+			chirp.Poll = &poll
+		}
+	}
+	return nil
+}
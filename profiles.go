@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/profile"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Profile is what anyone can see about a user. Unlike User it never
+// carries the email address.
+type Profile struct {
+	ID             uuid.UUID `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Handle         *string   `json:"handle"`
+	DisplayName    string    `json:"display_name"`
+	Bio            string    `json:"bio"`
+	Website        string    `json:"website"`
+	AvatarURL      string    `json:"avatar_url"`
+	ChirpyRed      bool      `json:"is_chirpy_red"`
+	FollowerCount  int32     `json:"follower_count"`
+	FollowingCount int32     `json:"following_count"`
+
+	// updatedAt feeds the Last-Modified of chirps that embed the profile.
+	updatedAt time.Time
+}
+
+// getProfileHandler looks a user up by ID or by handle, with or without a
+// leading "@". Handles can't be mistaken for IDs, so one route serves both.
+func (cfg *apiConfig) getProfileHandler(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("user")
+	var (
+		user database.User
+		err  error
+	)
+	if id, parseErr := uuid.Parse(key); parseErr == nil {
+		user, err = cfg.dbQueries.GetUserByID(r.Context(), id)
+	} else {
+		user, err = cfg.dbQueries.GetUserByHandle(r.Context(), strings.TrimPrefix(key, "@"))
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, profileFromDB(user))
+}
+
+// updateProfileHandler changes the caller's public profile. Only the fields
+// sent are changed; empty strings clear everything but the handle, which
+// can be changed but not removed.
+func (cfg *apiConfig) updateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Handle      *string `json:"handle"`
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
+		Website     *string `json:"website"`
+		AvatarURL   *string `json:"avatar_url"`
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	update := database.UpdateProfileParams{ID: validatedUserID}
+	fields := []struct {
+		value     *string
+		normalize func(string) (string, error)
+		name      string
+		dest      *sql.NullString
+	}{
+		{params.Handle, profile.NormalizeHandle, "handle", &update.Handle},
+		{params.DisplayName, profile.NormalizeDisplayName, "display name", &update.DisplayName},
+		{params.Bio, profile.NormalizeBio, "bio", &update.Bio},
+		{params.Website, profile.NormalizeURL, "website", &update.Website},
+		{params.AvatarURL, profile.NormalizeURL, "avatar URL", &update.AvatarUrl},
+	}
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+		normalized, err := field.normalize(*field.value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid "+field.name+": "+err.Error(), err)
+			return
+		}
+		*field.dest = sql.NullString{String: normalized, Valid: true}
+	}
+
+	user, err := cfg.dbQueries.UpdateProfile(r.Context(), update)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "Handle is already taken", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update profile", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, userFromDB(user))
+}
+
+// wantsAuthors reports whether the request asked for chirps to carry their
+// authors' profiles, with include=author.
+func wantsAuthors(r *http.Request) bool {
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(include) == "author" {
+			return true
+		}
+	}
+	return false
+}
+
+// embedAuthors fills in Author on each chirp and on the chirps they
+// rechirp or quote.
+func (cfg *apiConfig) embedAuthors(ctx context.Context, chirps []Chirp) error {
+	targets := []*Chirp{}
+	for i := range chirps {
+		targets = append(targets, &chirps[i])
+		if chirps[i].RechirpOf != nil {
+			targets = append(targets, chirps[i].RechirpOf)
+		}
+		if chirps[i].QuotedChirp != nil {
+			targets = append(targets, chirps[i].QuotedChirp)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	seen := map[uuid.UUID]bool{}
+	ids := []uuid.UUID{}
+	for _, chirp := range targets {
+		if !seen[chirp.UserID] {
+			seen[chirp.UserID] = true
+			ids = append(ids, chirp.UserID)
+		}
+	}
+	users, err := cfg.dbQueries.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	profiles := make(map[uuid.UUID]*Profile, len(users))
+	for _, user := range users {
+		p := profileFromDB(user)
+		profiles[user.ID] = &p
+	}
+	for _, chirp := range targets {
+		chirp.Author = profiles[chirp.UserID]
+	}
+	return nil
+}
+
+func profileFromDB(user database.User) Profile {
+	return Profile{
+		ID:             user.ID,
+		CreatedAt:      user.CreatedAt,
+		Handle:         nullStringPtr(user.Handle),
+		DisplayName:    user.DisplayName,
+		Bio:            user.Bio,
+		Website:        user.Website,
+		AvatarURL:      user.AvatarUrl,
+		ChirpyRed:      user.IsChirpyRed,
+		FollowerCount:  user.FollowerCount,
+		FollowingCount: user.FollowingCount,
+		updatedAt:      user.UpdatedAt,
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) rechirpHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	original, err := amplifiedChirp(r.Context(), qtx, id, validatedUserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if !amplifiable(original) {
+		respondWithError(w, http.StatusBadRequest, "Only public and unlisted chirps can be rechirped", nil)
+		return
+	}
+	// A rechirp of an ephemeral chirp expires along with it.
+	rechirpParams := database.CreateRechirpParams{
+		UserID:      validatedUserID,
+		RechirpOfID: uuid.NullUUID{UUID: original.ID, Valid: true},
+		ExpiresAt:   original.ExpiresAt,
+	}
+	status := http.StatusCreated
+	rechirp, err := qtx.CreateRechirp(r.Context(), rechirpParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already rechirped; hand back the existing one.
+		status = http.StatusOK
+		rechirp, err = qtx.GetRechirp(r.Context(), database.GetRechirpParams{
+			UserID:      rechirpParams.UserID,
+			RechirpOfID: rechirpParams.RechirpOfID,
+		})
+	} else if err == nil {
+		err = qtx.AdjustRechirpCounts(r.Context(), database.AdjustRechirpCountsParams{
+			RechirpDelta: 1,
+			ID:           original.ID,
+		})
+		original.RechirpCount++
+		if err == nil {
+			err = fanOutChirp(r.Context(), qtx, rechirp)
+		}
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp", err)
+		return
+	}
+
+	response := chirpFromDB(rechirp)
+	embedded := chirpFromDB(original)
+	response.RechirpOf = &embedded
+	respondWithJSON(w, status, response)
+}
+
+func (cfg *apiConfig) undoRechirpHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	original, err := amplifiedChirp(r.Context(), qtx, id, validatedUserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	rechirp, err := qtx.GetRechirp(r.Context(), database.GetRechirpParams{
+		UserID:      validatedUserID,
+		RechirpOfID: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err == nil {
+		err = qtx.DeleteChrip(r.Context(), rechirp.ID)
+	}
+	if err == nil {
+		err = adjustChirpReferences(r.Context(), qtx, rechirp, -1)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) quoteChirpHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	checked, violations, err := cfg.validateChirp(r.Context(), params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load word filters", err)
+		return
+	}
+	if len(violations) > 0 {
+		respondWithViolations(w, violations)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	quoted, err := amplifiedChirp(r.Context(), qtx, id, validatedUserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if !amplifiable(quoted) {
+		respondWithError(w, http.StatusBadRequest, "Only public and unlisted chirps can be quoted", nil)
+		return
+	}
+	chirp, err := qtx.CreateChirp(r.Context(), database.CreateChirpParams{
+		Body:          checked.Body,
+		UserID:        validatedUserID,
+		QuotedChirpID: uuid.NullUUID{UUID: quoted.ID, Valid: true},
+		Visibility:    visibilityPublic,
+	})
+	if err == nil {
+		err = tagChirp(r.Context(), qtx, chirp)
+	}
+	if err == nil {
+		err = flagChirp(r.Context(), qtx, chirp.ID, checked.Flagged)
+	}
+	if err == nil {
+		err = qtx.AdjustRechirpCounts(r.Context(), database.AdjustRechirpCountsParams{
+			QuoteDelta: 1,
+			ID:         quoted.ID,
+		})
+		quoted.QuoteCount++
+	}
+	if err == nil {
+		err = fanOutChirp(r.Context(), qtx, chirp)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
+	cfg.queueLinkPreview(chirp)
+
+	response := chirpFromDB(chirp)
+	embedded := chirpFromDB(quoted)
+	response.QuotedChirp = &embedded
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// amplifiedChirp locks and returns the chirp a rechirp or quote should point
+// at. A plain rechirp stands in for its original, so amplifying one amplifies
+// the original. Missing and tombstoned chirps, and chirps userID can't see,
+// all report sql.ErrNoRows.
+func amplifiedChirp(ctx context.Context, q *database.Queries, id, userID uuid.UUID) (database.Chirp, error) {
+	chirp, err := q.ChirpsByIdForUpdate(ctx, id)
+	if err == nil && chirp.RechirpOfID.Valid {
+		chirp, err = q.ChirpsByIdForUpdate(ctx, chirp.RechirpOfID.UUID)
+	}
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.TombstonedAt.Valid {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	visible, err := canViewChirp(ctx, q, chirp, userID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if !visible {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// amplifiable reports whether a chirp may be rechirped or quoted. Anything
+// narrower than unlisted would be shown to people it wasn't meant for, and
+// chirps hidden by moderators stay with their author.
+func amplifiable(chirp database.Chirp) bool {
+	if chirp.HiddenAt.Valid {
+		return false
+	}
+	return chirp.Visibility == visibilityPublic || chirp.Visibility == visibilityUnlisted
+}
+
+// adjustChirpReferences moves the counts of the chirp a rechirp or quote
+// points at by delta: -1 when it goes away, +1 when it comes back.
+func adjustChirpReferences(ctx context.Context, q *database.Queries, chirp database.Chirp, delta int32) error {
+	switch {
+	case chirp.RechirpOfID.Valid:
+		return q.AdjustRechirpCounts(ctx, database.AdjustRechirpCountsParams{
+			RechirpDelta: delta,
+			ID:           chirp.RechirpOfID.UUID,
+		})
+	case chirp.QuotedChirpID.Valid:
+		return q.AdjustRechirpCounts(ctx, database.AdjustRechirpCountsParams{
+			QuoteDelta: delta,
+			ID:         chirp.QuotedChirpID.UUID,
+		})
+	}
+	return nil
+}
+
+// embedReferencedChirps inlines the originals of rechirps and quotes that
+// viewerID can see.
+func (cfg *apiConfig) embedReferencedChirps(ctx context.Context, viewerID uuid.UUID, chirps []Chirp) error {
+	ids := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.RechirpOfID != nil {
+			ids = append(ids, *chirp.RechirpOfID)
+		}
+		if chirp.QuotedChirpID != nil {
+			ids = append(ids, *chirp.QuotedChirpID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	dbReferenced, err := cfg.dbQueries.ChirpsByIds(ctx, database.ChirpsByIdsParams{
+		Ids:      ids,
+		ViewerID: viewerParam(viewerID),
+	})
+	if err != nil {
+		return err
+	}
+	referenced := make(map[uuid.UUID]Chirp, len(dbReferenced))
+	for _, dbChirp := range dbReferenced {
+		referenced[dbChirp.ID] = chirpFromDB(dbChirp)
+	}
+	for i := range chirps {
+		if chirps[i].RechirpOfID != nil {
+			if original, ok := referenced[*chirps[i].RechirpOfID]; ok {
+				chirps[i].RechirpOf = &original
+			}
+		}
+		if chirps[i].QuotedChirpID != nil {
+			if quoted, ok := referenced[*chirps[i].QuotedChirpID]; ok {
+				chirps[i].QuotedChirp = &quoted
+			}
+		}
+	}
+	return nil
+}
@@ -27,6 +27,10 @@ func (cfg *apiConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusUnauthorized, "Failed to retrieve user for refresh token", err)
 		return
 	}
+	if isSuspended(userRecord.AccountState, userRecord.SuspendedUntil, time.Now().UTC()) {
+		respondWithSuspended(w, userRecord.SuspendedUntil)
+		return
+	}
 	newToken, err := auth.MakeJWT(userRecord.ID, cfg.jwtSecret)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to generate new token", err)
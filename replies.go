@@ -0,0 +1,168 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultThreadDepth = 10
+	maxThreadDepth     = 50
+	maxThreadReplies   = 500
+)
+
+// ThreadNode is a chirp together with the replies below it in a thread.
+type ThreadNode struct {
+	Chirp
+	Replies []*ThreadNode `json:"replies"`
+}
+
+func (cfg *apiConfig) getChirpRepliesHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	_, err = cfg.viewableChirp(r, cfg.dbQueries, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+
+	viewerID, _ := cfg.viewerID(r)
+	cursorCreatedAt, cursorID := page.cursorArgs()
+	dbChirps, err := cfg.dbQueries.GetChirpReplies(r.Context(), database.GetChirpRepliesParams{
+		ParentChirpID:   uuid.NullUUID{UUID: id, Valid: true},
+		ViewerID:        viewerParam(viewerID),
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve replies", err)
+		return
+	}
+
+	dbChirps, next, _ := paginate(dbChirps, page, chirpCursor)
+	chirps := []Chirp{}
+	for _, dbChirp := range dbChirps {
+		chirps = append(chirps, chirpFromDB(dbChirp))
+	}
+	if err := cfg.decorateChirps(r, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, chirps)
+}
+
+func (cfg *apiConfig) getChirpThreadHandler(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Ancestors []Chirp    `json:"ancestors"`
+		Chirp     ThreadNode `json:"chirp"`
+		Truncated bool       `json:"truncated"`
+	}
+
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	depth := defaultThreadDepth
+	if depthString := r.URL.Query().Get("depth"); depthString != "" {
+		depth, err = strconv.Atoi(depthString)
+		if err != nil || depth < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid depth", err)
+			return
+		}
+		depth = min(depth, maxThreadDepth)
+	}
+
+	chirp, err := cfg.viewableChirp(r, cfg.dbQueries, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	// Hidden chirps are left out along with everything beyond them, so the
+	// thread stops at the first ancestor or reply the viewer can't see.
+	viewerID, _ := cfg.viewerID(r)
+	dbAncestors, err := cfg.dbQueries.GetChirpAncestors(r.Context(), database.GetChirpAncestorsParams{
+		ChirpID:  id,
+		ViewerID: viewerParam(viewerID),
+		MaxDepth: int32(depth),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve thread", err)
+		return
+	}
+	dbDescendants, err := cfg.dbQueries.GetChirpDescendants(r.Context(), database.GetChirpDescendantsParams{
+		ChirpID:  uuid.NullUUID{UUID: id, Valid: true},
+		ViewerID: viewerParam(viewerID),
+		MaxDepth: int32(depth),
+		Limit:    maxThreadReplies + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve thread", err)
+		return
+	}
+	truncated := len(dbDescendants) > maxThreadReplies
+	if truncated {
+		dbDescendants = dbDescendants[:maxThreadReplies]
+	}
+
+	// Decorate every chirp in one pass: ancestors, then the chirp itself,
+	// then its descendants.
+	chirps := []Chirp{}
+	for _, dbAncestor := range dbAncestors {
+		chirps = append(chirps, chirpFromDB(dbAncestor))
+	}
+	chirps = append(chirps, chirpFromDB(chirp))
+	for _, dbDescendant := range dbDescendants {
+		chirps = append(chirps, chirpFromDB(dbDescendant))
+	}
+	if err := cfg.decorateChirps(r, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+	ancestors := chirps[:len(dbAncestors)]
+	descendants := chirps[len(dbAncestors)+1:]
+
+	// Descendants come back oldest first, so a reply's parent is always
+	// already in the tree when the reply is reached.
+	root := &ThreadNode{Chirp: chirps[len(dbAncestors)], Replies: []*ThreadNode{}}
+	nodes := map[uuid.UUID]*ThreadNode{chirp.ID: root}
+	for _, descendant := range descendants {
+		node := &ThreadNode{Chirp: descendant, Replies: []*ThreadNode{}}
+		nodes[descendant.ID] = node
+		if parent, ok := nodes[*descendant.InReplyTo]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		Ancestors: ancestors,
+		Chirp:     *root,
+		Truncated: truncated,
+	})
+}
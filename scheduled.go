@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/validation"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	maxScheduleAhead         = 365 * 24 * time.Hour
+	scheduledPublishInterval = 15 * time.Second
+)
+
+type ScheduledChirp struct {
+	ID           uuid.UUID   `json:"id"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+	UserID       uuid.UUID   `json:"user_id"`
+	Body         string      `json:"body"`
+	InReplyTo    *uuid.UUID  `json:"in_reply_to"`
+	PublishAt    time.Time   `json:"publish_at"`
+	PublishError *string     `json:"publish_error,omitempty"`
+	Visibility   string      `json:"visibility"`
+	Mentions     []uuid.UUID `json:"mentions"`
+}
+
+// scheduleChirp stores an already validated chirp to be published at
+// publishAt. The chirp keeps its ID when it's published.
+func (cfg *apiConfig) scheduleChirp(w http.ResponseWriter, r *http.Request, userID uuid.UUID, parentID uuid.NullUUID, checked validation.Chirp, audience chirpAudience, publishAt time.Time) {
+	now := time.Now().UTC()
+	publishAt = publishAt.UTC()
+	if !publishAt.After(now) {
+		respondWithError(w, http.StatusBadRequest, "publish_at must be in the future", nil)
+		return
+	}
+	if publishAt.After(now.Add(maxScheduleAhead)) {
+		respondWithError(w, http.StatusBadRequest, "publish_at is too far in the future", nil)
+		return
+	}
+	if parentID.Valid {
+		parent, err := cfg.dbQueries.ChirpsById(r.Context(), parentID.UUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, errParentNotFound.Error(), nil)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+			return
+		}
+		visible, err := canViewChirp(r.Context(), cfg.dbQueries, parent, userID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+			return
+		}
+		if !visible {
+			respondWithError(w, http.StatusNotFound, errParentNotFound.Error(), nil)
+			return
+		}
+		if parent.RechirpOfID.Valid {
+			respondWithError(w, http.StatusBadRequest, errReplyToRechirp.Error(), nil)
+			return
+		}
+	}
+
+	flagged := checked.Flagged
+	if flagged == nil {
+		flagged = []string{}
+	}
+	scheduled, err := cfg.dbQueries.CreateScheduledChirp(r.Context(), database.CreateScheduledChirpParams{
+		UserID:        userID,
+		Body:          checked.Body,
+		ParentChirpID: parentID,
+		FlaggedWords:  flagged,
+		PublishAt:     publishAt,
+		Visibility:    audience.Visibility,
+		Mentions:      audience.Mentions,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't schedule chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, scheduledChirpFromDB(scheduled))
+}
+
+func (cfg *apiConfig) getScheduledChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorPublishAt, cursorID := page.cursorArgs()
+	dbScheduled, err := cfg.dbQueries.GetScheduledChirps(r.Context(), database.GetScheduledChirpsParams{
+		UserID:          validatedUserID,
+		CursorPublishAt: cursorPublishAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve scheduled chirps", err)
+		return
+	}
+
+	dbScheduled, next, _ := paginate(dbScheduled, page, func(scheduled database.ScheduledChirp) pageCursor {
+		return pageCursor{CreatedAt: scheduled.PublishAt, ID: scheduled.ID}
+	})
+	scheduled := []ScheduledChirp{}
+	for _, dbChirp := range dbScheduled {
+		scheduled = append(scheduled, scheduledChirpFromDB(dbChirp))
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, scheduled)
+}
+
+func (cfg *apiConfig) cancelScheduledChirpHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteScheduledChirp(r.Context(), database.DeleteScheduledChirpParams{
+		ID:     id,
+		UserID: validatedUserID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't cancel scheduled chirp", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// publishScheduledLoop publishes due chirps every interval until ctx is done.
+func (cfg *apiConfig) publishScheduledLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			published, err := cfg.publishNextScheduledChirp(ctx)
+			if err != nil {
+				log.Printf("Error publishing scheduled chirp: %s", err)
+			}
+			if !published {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishNextScheduledChirp publishes one due chirp in its own transaction.
+// The row stays locked until the transaction ends and other instances skip
+// locked rows, so each chirp is published exactly once. A chirp that can
+// never be published, because its parent has gone, it breaks a constraint
+// or its author is suspended when it falls due, is marked with the reason
+// and left for its author to cancel. Otherwise it would come up first on
+// every run and hold up everything due after it.
+func (cfg *apiConfig) publishNextScheduledChirp(ctx context.Context) (bool, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	scheduled, err := qtx.GetDueScheduledChirp(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	author, err := qtx.GetUserAccountState(ctx, scheduled.UserID)
+	if err != nil {
+		return false, err
+	}
+	var chirp database.Chirp
+	if isSuspended(author.AccountState, author.SuspendedUntil, time.Now()) {
+		err = errAuthorSuspended
+	} else {
+		chirp, err = insertChirp(ctx, qtx, database.CreateChirpParams{
+			ID:            uuid.NullUUID{UUID: scheduled.ID, Valid: true},
+			Body:          scheduled.Body,
+			UserID:        scheduled.UserID,
+			ParentChirpID: scheduled.ParentChirpID,
+			Visibility:    scheduled.Visibility,
+		}, scheduled.FlaggedWords, scheduled.Mentions)
+	}
+	if errors.Is(err, errParentNotFound) || errors.Is(err, errReplyToRechirp) {
+		err = qtx.SetScheduledChirpError(ctx, database.SetScheduledChirpErrorParams{
+			ID:           scheduled.ID,
+			PublishError: sql.NullString{String: err.Error(), Valid: true},
+		})
+	} else if err == nil {
+		err = fanOutChirp(ctx, qtx, chirp)
+		if err == nil {
+			_, err = qtx.DeleteScheduledChirp(ctx, database.DeleteScheduledChirpParams{
+				ID:     scheduled.ID,
+				UserID: scheduled.UserID,
+			})
+		}
+	}
+	if permanentPublishError(err) {
+		// The failed statement aborted the transaction, so the mark has to
+		// go in outside it.
+		tx.Rollback()
+		log.Printf("Can't publish scheduled chirp %s: %s", scheduled.ID, err)
+		reason := "Chirp couldn't be published"
+		if errors.Is(err, errAuthorSuspended) {
+			reason = err.Error()
+		}
+		err = cfg.dbQueries.SetScheduledChirpError(ctx, database.SetScheduledChirpErrorParams{
+			ID:           scheduled.ID,
+			PublishError: sql.NullString{String: reason, Valid: true},
+		})
+		return err == nil, err
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	if chirp.ID != uuid.Nil {
+		cfg.queueLinkPreview(chirp)
+	}
+	return true, nil
+}
+
+// errAuthorSuspended stops a scheduled chirp from going out while its author
+// is locked out, the same as posting it by hand would.
+var errAuthorSuspended = errors.New("Account is suspended")
+
+// permanentPublishError reports whether err means the chirp can't be
+// published as it stands, such as a constraint it breaks or a suspended
+// author, rather than a database hiccup worth retrying.
+func permanentPublishError(err error) bool {
+	if errors.Is(err, errAuthorSuspended) {
+		return true
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code.Class() {
+	case "22", "23": // data exception, integrity constraint violation
+		return true
+	}
+	return false
+}
+
+func scheduledChirpFromDB(scheduled database.ScheduledChirp) ScheduledChirp {
+	var publishError *string
+	if scheduled.PublishError.Valid {
+		publishError = &scheduled.PublishError.String
+	}
+	return ScheduledChirp{
+		ID:           scheduled.ID,
+		CreatedAt:    scheduled.CreatedAt,
+		UpdatedAt:    scheduled.UpdatedAt,
+		UserID:       scheduled.UserID,
+		Body:         scheduled.Body,
+		InReplyTo:    nullUUIDPtr(scheduled.ParentChirpID),
+		PublishAt:    scheduled.PublishAt,
+		PublishError: publishError,
+		Visibility:   scheduled.Visibility,
+		Mentions:     scheduled.Mentions,
+	}
+}
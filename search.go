@@ -0,0 +1,126 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/search"
+)
+
+const (
+	maxSearchOffset = 1000
+	// Postgres marks matches with these control characters so the snippet
+	// can be HTML-escaped before the <mark> tags go in.
+	snippetStart    = "\x02"
+	snippetStop     = "\x03"
+	headlineOptions = "StartSel=" + snippetStart + ", StopSel=" + snippetStop + ", MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+type SearchResult struct {
+	Chirp
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// searchChirpsHandler runs a full-text search over chirp bodies. Results are
+// ordered by relevance, so pages are addressed by offset; the cursor tokens
+// are opaque to clients just like the ones on GET /api/chirps.
+func (cfg *apiConfig) searchChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := search.Parse(r.URL.Query().Get("q"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid search query: "+err.Error(), err)
+		return
+	}
+	if query.Text == "" && !query.From.Valid && query.Since.IsZero() && query.Until.IsZero() {
+		respondWithError(w, http.StatusBadRequest, "Search query is empty", nil)
+		return
+	}
+	limit, err := parseLimit(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	offset := 0
+	cursor := r.URL.Query().Get("cursor")
+	if cursor == "" {
+		cursor = r.URL.Query().Get("after")
+	}
+	if cursor != "" {
+		offset, err = decodeSearchCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+			return
+		}
+	}
+
+	rows, err := cfg.dbQueries.SearchChirps(r.Context(), database.SearchChirpsParams{
+		HeadlineOptions: headlineOptions,
+		Query:           query.Text,
+		UserID:          query.From,
+		Since:           sql.NullTime{Time: query.Since, Valid: !query.Since.IsZero()},
+		Until:           sql.NullTime{Time: query.Until, Valid: !query.Until.IsZero()},
+		Limit:           limit + 1,
+		Offset:          int32(offset),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+	next := ""
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		if nextOffset := offset + int(limit); nextOffset <= maxSearchOffset {
+			next = encodeSearchCursor(nextOffset)
+		}
+	}
+
+	chirps := []Chirp{}
+	for _, row := range rows {
+		chirps = append(chirps, chirpFromDB(row.Chirp))
+	}
+	if err := cfg.decorateChirps(r, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+	results := []SearchResult{}
+	for i, row := range rows {
+		results = append(results, SearchResult{
+			Chirp:   chirps[i],
+			Rank:    row.Rank,
+			Snippet: highlightSnippet(row.Snippet),
+		})
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+// highlightSnippet escapes a ts_headline snippet and swaps the match markers
+// for <mark> tags, so clients can render it as HTML.
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, snippetStart, "<mark>")
+	return strings.ReplaceAll(escaped, snippetStop, "</mark>")
+}
+
+func encodeSearchCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeSearchCursor(token string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, errors.New("malformed cursor")
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 || offset > maxSearchOffset {
+		return 0, errors.New("malformed cursor")
+	}
+	return offset, nil
+}
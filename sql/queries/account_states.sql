@@ -0,0 +1,27 @@
+-- name: SetAccountState :one
+UPDATE users
+SET account_state = $2, suspended_until = $3, state_reason = $4, state_changed_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateAccountStateChange :one
+INSERT INTO account_state_changes (id, created_at, user_id, moderator, account_state, suspended_until, reason)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: GetAccountStateChanges :many
+SELECT * FROM account_state_changes
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetUserAccountState :one
+SELECT account_state, suspended_until FROM users
+WHERE id = $1;
@@ -0,0 +1,14 @@
+-- name: GetChirpsForExport :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+    AND rechirp_of_id IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ChirpIDReserved :one
+SELECT EXISTS (SELECT 1 FROM drafts WHERE drafts.id = sqlc.arg('id'))
+    OR EXISTS (SELECT 1 FROM scheduled_chirps WHERE scheduled_chirps.id = sqlc.arg('id')) AS reserved;
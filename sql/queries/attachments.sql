@@ -0,0 +1,51 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, thumbnail_content_type, alt_text)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+)
+RETURNING *;
+
+-- name: GetAttachment :one
+SELECT * FROM attachments
+WHERE id = $1;
+
+-- name: AttachToChirp :execrows
+UPDATE attachments
+SET chirp_id = sqlc.arg('chirp_id'),
+    position = sqlc.arg('position'),
+    alt_text = COALESCE(sqlc.narg('alt_text')::text, alt_text),
+    attached_at = NOW()
+WHERE id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id')
+    AND attached_at IS NULL;
+
+-- name: GetChirpAttachments :many
+SELECT * FROM attachments
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: DetachChirpAttachments :exec
+UPDATE attachments
+SET chirp_id = NULL
+WHERE chirp_id = $1;
+
+-- name: DeleteOrphanedAttachments :many
+DELETE FROM attachments
+WHERE id IN (
+    SELECT id FROM attachments
+    WHERE chirp_id IS NULL AND created_at < sqlc.arg('created_before')
+    ORDER BY created_at
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
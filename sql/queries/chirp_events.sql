@@ -0,0 +1,17 @@
+-- name: GetChirpEventsAfter :many
+SELECT * FROM chirp_events
+WHERE id > sqlc.arg('after_id')
+    OR id = ANY(sqlc.arg('pending_ids')::bigint[])
+ORDER BY id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetLatestChirpEventID :one
+SELECT COALESCE(MAX(id), 0)::bigint AS id
+FROM chirp_events;
+
+-- name: DeleteChirpEventsBefore :exec
+DELETE FROM chirp_events
+WHERE created_at < sqlc.arg('created_before')::timestamp;
+
+-- name: QuietChirpEvents :exec
+SELECT set_config('chirpy.quiet_chirp_events', 'on', true);
@@ -0,0 +1,35 @@
+-- name: LikeChirp :execrows
+INSERT INTO chirp_likes (chirp_id, user_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: UnlikeChirp :execrows
+DELETE FROM chirp_likes
+WHERE chirp_id = $1 AND user_id = $2;
+
+-- name: IncrementLikeCount :one
+UPDATE chirps
+SET like_count = like_count + 1,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DecrementLikeCount :one
+UPDATE chirps
+SET like_count = GREATEST(like_count - 1, 0),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetChirpLikes :many
+SELECT * FROM chirp_likes
+WHERE chirp_id = sqlc.arg('chirp_id')
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, user_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_user_id')::uuid))
+ORDER BY created_at DESC, user_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetLikedChirpIDs :many
+SELECT chirp_id FROM chirp_likes
+WHERE user_id = sqlc.arg('user_id')
+    AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
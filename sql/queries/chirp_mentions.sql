@@ -0,0 +1,6 @@
+-- name: AddChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, user_id)
+SELECT sqlc.arg('chirp_id'), users.id
+FROM users
+WHERE users.id = ANY(sqlc.arg('user_ids')::uuid[])
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
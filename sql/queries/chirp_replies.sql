@@ -0,0 +1,62 @@
+-- name: IncrementReplyCount :exec
+UPDATE chirps
+SET reply_count = reply_count + 1,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: DecrementReplyCount :one
+UPDATE chirps
+SET reply_count = GREATEST(reply_count - 1, 0),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ChirpHasReplies :one
+SELECT EXISTS (
+    SELECT 1 FROM chirps
+    WHERE parent_chirp_id = $1
+) AS has_replies;
+
+-- name: GetChirpReplies :many
+SELECT * FROM chirps
+WHERE parent_chirp_id = sqlc.arg('parent_chirp_id')
+    AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors (id, parent_chirp_id, depth) AS (
+    SELECT c.id, c.parent_chirp_id, 1
+    FROM chirps c
+    WHERE c.id = (SELECT p.parent_chirp_id FROM chirps p WHERE p.id = sqlc.arg('chirp_id'))
+        AND chirp_visible_to(c.id, c.user_id, c.visibility, sqlc.narg('viewer_id')::uuid)
+    UNION ALL
+    SELECT c.id, c.parent_chirp_id, a.depth + 1
+    FROM chirps c
+    JOIN ancestors a ON c.id = a.parent_chirp_id
+    WHERE a.depth < sqlc.arg('max_depth')::int
+        AND chirp_visible_to(c.id, c.user_id, c.visibility, sqlc.narg('viewer_id')::uuid)
+)
+SELECT chirps.* FROM chirps
+JOIN ancestors ON chirps.id = ancestors.id
+ORDER BY ancestors.depth DESC;
+
+-- name: GetChirpDescendants :many
+WITH RECURSIVE descendants (id, depth) AS (
+    SELECT c.id, 1
+    FROM chirps c
+    WHERE c.parent_chirp_id = sqlc.arg('chirp_id')
+        AND chirp_visible_to(c.id, c.user_id, c.visibility, sqlc.narg('viewer_id')::uuid)
+    UNION ALL
+    SELECT c.id, d.depth + 1
+    FROM chirps c
+    JOIN descendants d ON c.parent_chirp_id = d.id
+    WHERE d.depth < sqlc.arg('max_depth')::int
+        AND chirp_visible_to(c.id, c.user_id, c.visibility, sqlc.narg('viewer_id')::uuid)
+)
+SELECT chirps.* FROM chirps
+JOIN descendants ON chirps.id = descendants.id
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg('limit');
@@ -1,10 +1,15 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_chirp_id, root_chirp_id, quoted_chirp_id, visibility, expires_at)
 VALUES (
-    gen_random_uuid(),
-    NOW(),
-    NOW(),
+    COALESCE(sqlc.narg('id')::uuid, gen_random_uuid()),
+    COALESCE(sqlc.narg('created_at')::timestamp, NOW()),
+    COALESCE(sqlc.narg('created_at')::timestamp, NOW()),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
 )
-RETURNING *;
\ No newline at end of file
+RETURNING *;
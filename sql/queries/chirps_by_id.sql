@@ -1,3 +1,30 @@
 -- name: ChirpsById :one
 SELECT * FROM chirps
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1 AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: ChirpsByIdForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+FOR UPDATE;
+
+-- name: ChirpsByIds :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]) AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid);
+
+-- name: ChirpVisibleTo :one
+SELECT chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid) AS visible
+FROM chirps
+WHERE id = sqlc.arg('id');
+
+-- name: ChirpsByIdIncludingDeleted :one
+SELECT * FROM chirps
+WHERE id = $1;
+
+-- name: ChirpsByIdIncludingDeletedForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1
+FOR UPDATE;
@@ -1,4 +1,21 @@
--- name: GetChirpsByUserId :many
+-- name: GetChirpsByUserIdAsc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
\ No newline at end of file
+WHERE user_id = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpsByUserIdDesc :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,36 @@
+-- name: CreateDraft :one
+INSERT INTO drafts (id, created_at, updated_at, user_id, body, parent_chirp_id, visibility, mentions)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $1, parent_chirp_id = $2, visibility = $3, mentions = $4, updated_at = NOW()
+WHERE id = $5 AND user_id = $6
+RETURNING *;
+
+-- name: GetDrafts :many
+SELECT * FROM drafts
+WHERE user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('cursor_updated_at')::timestamp IS NULL
+        OR (updated_at, id) < (sqlc.narg('cursor_updated_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetDraftForUpdate :one
+SELECT * FROM drafts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE;
+
+-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE id = $1 AND user_id = $2;
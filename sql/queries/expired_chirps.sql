@@ -0,0 +1,8 @@
+-- name: GetExpiredChirps :many
+SELECT * FROM chirps
+WHERE expires_at <= sqlc.arg('expired_before')::timestamp
+    AND tombstoned_at IS NULL
+    AND rechirp_of_id IS NULL
+ORDER BY expires_at ASC
+LIMIT sqlc.arg('limit')
+FOR UPDATE SKIP LOCKED;
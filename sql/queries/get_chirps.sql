@@ -1,3 +1,29 @@
--- name: GetChirps :many
+-- name: GetChirpsAsc :many
 SELECT * FROM chirps
-ORDER BY created_at ASC;
\ No newline at end of file
+WHERE deleted_at IS NULL
+    AND visibility = 'public'
+    AND hidden_at IS NULL
+    AND (user_id = sqlc.narg('viewer_id')::uuid OR NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id AND users.account_state = 'shadow_banned'
+    ))
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpsDesc :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+    AND visibility = 'public'
+    AND hidden_at IS NULL
+    AND (user_id = sqlc.narg('viewer_id')::uuid OR NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id AND users.account_state = 'shadow_banned'
+    ))
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
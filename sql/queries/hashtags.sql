@@ -0,0 +1,59 @@
+-- name: UpsertHashtag :one
+INSERT INTO hashtags (id, created_at, tag)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1
+)
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING *;
+
+-- name: AddChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (chirp_id, hashtag_id) DO NOTHING;
+
+-- name: ClearChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: GetChirpsByHashtag :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg('tag')
+    AND chirps.deleted_at IS NULL
+    AND chirps.hidden_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id AND users.account_state = 'shadow_banned'
+    )
+    AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (chirp_hashtags.created_at, chirp_hashtags.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY chirp_hashtags.created_at DESC, chirp_hashtags.chirp_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetTrendingHashtags :many
+SELECT tag, recent_uses, previous_uses, (recent_uses - previous_uses)::int AS velocity
+FROM (
+    SELECT hashtags.tag,
+        (COUNT(*) FILTER (WHERE chirp_hashtags.created_at >= sqlc.arg('window_start')::timestamp))::int AS recent_uses,
+        (COUNT(*) FILTER (WHERE chirp_hashtags.created_at < sqlc.arg('window_start')::timestamp))::int AS previous_uses
+    FROM chirp_hashtags
+    JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+    JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+    JOIN users ON users.id = chirps.user_id
+    WHERE chirp_hashtags.created_at >= sqlc.arg('previous_window_start')::timestamp
+        AND chirps.visibility = 'public'
+        AND chirps.deleted_at IS NULL
+        AND chirps.hidden_at IS NULL
+        AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+        -- Suspensions with an end date lapse on their own.
+        AND (users.account_state = 'active'
+            OR (users.account_state = 'suspended' AND users.suspended_until <= NOW()))
+    GROUP BY hashtags.tag
+) usage
+WHERE recent_uses > 0
+ORDER BY velocity DESC, recent_uses DESC, tag ASC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,27 @@
+-- name: SaveLinkPreview :execrows
+WITH chirp AS (
+    UPDATE chirps
+    SET updated_at = NOW()
+    WHERE id = sqlc.arg('chirp_id')
+        AND body = sqlc.arg('chirp_body')::text
+        AND deleted_at IS NULL
+        AND tombstoned_at IS NULL
+    RETURNING id
+)
+INSERT INTO link_previews (chirp_id, created_at, url, title, description, image_url)
+SELECT id, NOW(), sqlc.arg('url')::text, sqlc.arg('title')::text, sqlc.arg('description')::text, sqlc.arg('image_url')::text
+FROM chirp
+ON CONFLICT (chirp_id) DO UPDATE
+SET created_at = EXCLUDED.created_at,
+    url = EXCLUDED.url,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url;
+
+-- name: GetLinkPreviews :many
+SELECT * FROM link_previews
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: DeleteLinkPreview :exec
+DELETE FROM link_previews
+WHERE chirp_id = $1;
@@ -0,0 +1,42 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, created_at, expires_at)
+VALUES ($1, NOW(), $2);
+
+-- name: CreatePollOption :exec
+INSERT INTO poll_options (chirp_id, position, text)
+VALUES ($1, $2, $3);
+
+-- name: GetPolls :many
+SELECT * FROM polls
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetPollOptions :many
+SELECT * FROM poll_options
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: GetPollVotesByUser :many
+SELECT * FROM poll_votes
+WHERE user_id = sqlc.arg('user_id')
+    AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: CastPollVote :execrows
+INSERT INTO poll_votes (chirp_id, user_id, position, created_at)
+SELECT polls.chirp_id, $2, $3, NOW()
+FROM polls
+WHERE polls.chirp_id = $1 AND polls.expires_at > NOW()
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: IncrementPollOptionVotes :exec
+WITH touched AS (
+    UPDATE chirps
+    SET updated_at = NOW()
+    WHERE id = $1
+)
+UPDATE poll_options
+SET vote_count = vote_count + 1
+WHERE chirp_id = $1 AND position = $2;
+
+-- name: DeletePoll :exec
+DELETE FROM polls
+WHERE chirp_id = $1;
@@ -0,0 +1,18 @@
+-- name: GetUserByHandle :one
+SELECT * FROM users
+WHERE lower(handle) = lower(sqlc.arg('handle'));
+
+-- name: GetUsersByIDs :many
+SELECT * FROM users
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: UpdateProfile :one
+UPDATE users
+SET handle = COALESCE(sqlc.narg('handle'), handle),
+    display_name = COALESCE(sqlc.narg('display_name'), display_name),
+    bio = COALESCE(sqlc.narg('bio'), bio),
+    website = COALESCE(sqlc.narg('website'), website),
+    avatar_url = COALESCE(sqlc.narg('avatar_url'), avatar_url),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
@@ -0,0 +1,28 @@
+-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of_id, expires_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    '',
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, rechirp_of_id) WHERE rechirp_of_id IS NOT NULL DO NOTHING
+RETURNING *;
+
+-- name: GetRechirp :one
+SELECT * FROM chirps
+WHERE user_id = $1 AND rechirp_of_id = $2;
+
+-- name: AdjustRechirpCounts :exec
+UPDATE chirps
+SET rechirp_count = GREATEST(rechirp_count + sqlc.arg('rechirp_delta')::int, 0),
+    quote_count = GREATEST(quote_count + sqlc.arg('quote_delta')::int, 0),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteRechirpsOf :exec
+DELETE FROM chirps
+WHERE rechirp_of_id = $1;
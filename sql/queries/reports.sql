@@ -0,0 +1,69 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, updated_at, chirp_id, chirp_user_id, chirp_body, reporter_id, reason, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT (chirp_id, reporter_id) DO NOTHING
+RETURNING *;
+
+-- name: GetReport :one
+SELECT * FROM reports
+WHERE id = $1;
+
+-- name: GetReportForUpdate :one
+SELECT * FROM reports
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetReports :many
+SELECT * FROM reports
+WHERE status = ANY(sqlc.arg('statuses')::text[])
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ClaimReport :one
+UPDATE reports
+SET status = 'claimed', claimed_by = sqlc.arg('moderator'), claimed_at = NOW(), updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ResolveReport :one
+UPDATE reports
+SET status = 'resolved', resolution = sqlc.arg('resolution'), resolved_by = sqlc.arg('moderator'),
+    resolved_at = NOW(), updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, created_at, report_id, moderator, action, chirp_id, user_id, note)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetModerationActions :many
+SELECT * FROM moderation_actions
+WHERE report_id = $1
+ORDER BY created_at ASC, id ASC;
+
+-- name: HideChirp :exec
+UPDATE chirps
+SET hidden_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND hidden_at IS NULL;
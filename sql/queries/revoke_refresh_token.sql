@@ -1,4 +1,9 @@
 -- name: RevokeRefreshToken :exec
 UPDATE refresh_tokens
 SET revoked_at = Now(), updated_at = NOW()
-WHERE token = $1;
\ No newline at end of file
+WHERE token = $1;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,39 @@
+-- name: CreateScheduledChirp :one
+INSERT INTO scheduled_chirps (id, created_at, updated_at, user_id, body, parent_chirp_id, flagged_words, publish_at, visibility, mentions)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
+
+-- name: GetScheduledChirps :many
+SELECT * FROM scheduled_chirps
+WHERE user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('cursor_publish_at')::timestamp IS NULL
+        OR (publish_at, id) > (sqlc.narg('cursor_publish_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY publish_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteScheduledChirp :execrows
+DELETE FROM scheduled_chirps
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetDueScheduledChirp :one
+SELECT * FROM scheduled_chirps
+WHERE publish_at <= NOW() AND publish_error IS NULL
+ORDER BY publish_at ASC
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: SetScheduledChirpError :exec
+UPDATE scheduled_chirps
+SET publish_error = $2, updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,21 @@
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps),
+    ts_rank(chirps.search_vector, tsq)::real AS rank,
+    ts_headline('english', chirps.body, tsq, sqlc.arg('headline_options')::text) AS snippet
+FROM chirps, websearch_to_tsquery('english', sqlc.arg('query')::text) tsq
+WHERE (sqlc.arg('query')::text = '' OR chirps.search_vector @@ tsq)
+    AND chirps.deleted_at IS NULL
+    AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+    AND chirps.visibility = 'public'
+    AND chirps.hidden_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id AND users.account_state = 'shadow_banned'
+    )
+    AND chirps.rechirp_of_id IS NULL
+    AND (sqlc.narg('user_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('user_id')::uuid)
+    AND (sqlc.narg('since')::timestamp IS NULL OR chirps.created_at >= sqlc.narg('since')::timestamp)
+    AND (sqlc.narg('until')::timestamp IS NULL OR chirps.created_at <= sqlc.narg('until')::timestamp)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
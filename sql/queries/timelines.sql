@@ -0,0 +1,89 @@
+-- name: FanOutChirp :exec
+INSERT INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT follows.follower_id, sqlc.arg('chirp_id'), follows.followee_id, sqlc.arg('created_at')
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.followee_id = sqlc.arg('author_id')
+    AND users.follower_count <= sqlc.arg('max_followers')
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: BackfillTimeline :execrows
+INSERT INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT follows.follower_id, recent.id, follows.followee_id, recent.created_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+CROSS JOIN LATERAL (
+    SELECT chirps.id, chirps.created_at FROM chirps
+    WHERE chirps.user_id = follows.followee_id
+        AND chirps.visibility <> 'private'
+        AND chirps.deleted_at IS NULL
+    ORDER BY chirps.created_at DESC, chirps.id DESC
+    LIMIT sqlc.arg('per_author')
+) recent
+WHERE follows.follower_id = sqlc.arg('user_id')
+    AND (sqlc.narg('author_id')::uuid IS NULL OR follows.followee_id = sqlc.narg('author_id')::uuid)
+    AND users.follower_count <= sqlc.arg('max_followers')
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: ClearTimeline :exec
+DELETE FROM timeline_entries
+WHERE user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('author_id')::uuid IS NULL OR author_id = sqlc.narg('author_id')::uuid);
+
+-- name: GetTimeline :many
+SELECT chirps.* FROM chirps
+WHERE chirps.id IN (
+    SELECT page.id FROM (
+        (
+            SELECT timeline_entries.chirp_id AS id, timeline_entries.created_at
+            FROM timeline_entries
+            JOIN chirps ON chirps.id = timeline_entries.chirp_id
+            WHERE timeline_entries.user_id = sqlc.arg('user_id')
+                AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+                    OR (timeline_entries.created_at, timeline_entries.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+                AND chirps.deleted_at IS NULL
+                AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+                AND chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.arg('user_id'))
+            ORDER BY timeline_entries.created_at DESC, timeline_entries.chirp_id DESC
+            LIMIT sqlc.arg('limit')
+        )
+        UNION
+        (
+            SELECT chirps.id, chirps.created_at
+            FROM chirps
+            WHERE chirps.user_id = sqlc.arg('user_id')
+                AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+                    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+                AND chirps.deleted_at IS NULL
+                AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+            ORDER BY chirps.created_at DESC, chirps.id DESC
+            LIMIT sqlc.arg('limit')
+        )
+        UNION
+        (
+            SELECT chirps.id, chirps.created_at
+            FROM follows
+            JOIN users ON users.id = follows.followee_id
+            JOIN chirps ON chirps.user_id = follows.followee_id
+            WHERE follows.follower_id = sqlc.arg('user_id')
+                AND users.follower_count > sqlc.arg('max_followers')
+                AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+                    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+                AND chirps.visibility <> 'private'
+                AND chirps.deleted_at IS NULL
+                AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+                AND chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.arg('user_id'))
+            ORDER BY chirps.created_at DESC, chirps.id DESC
+            LIMIT sqlc.arg('limit')
+        )
+    ) page
+    ORDER BY page.created_at DESC, page.id DESC
+    LIMIT sqlc.arg('limit')
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC;
+
+-- name: GetUserIDs :many
+SELECT id FROM users
+WHERE sqlc.narg('after')::uuid IS NULL OR id > sqlc.narg('after')::uuid
+ORDER BY id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,5 @@
+-- name: TombstoneChirp :one
+UPDATE chirps
+SET body = '', quoted_chirp_id = NULL, rechirp_count = 0, tombstoned_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,30 @@
+-- name: SoftDeleteChirp :one
+UPDATE chirps
+SET deleted_at = NOW(), rechirp_count = 0, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetTrashedChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+    AND deleted_at >= sqlc.arg('deleted_since')::timestamp
+    AND tombstoned_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND (sqlc.narg('cursor_deleted_at')::timestamp IS NULL
+        OR (deleted_at, id) < (sqlc.narg('cursor_deleted_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetExpiredTrash :many
+SELECT * FROM chirps
+WHERE deleted_at < sqlc.arg('deleted_before')::timestamp
+    AND tombstoned_at IS NULL
+ORDER BY deleted_at ASC
+LIMIT sqlc.arg('limit')
+FOR UPDATE SKIP LOCKED;
@@ -0,0 +1,5 @@
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW(), edited_at = NOW()
+WHERE id = $2
+RETURNING *;
@@ -7,4 +7,8 @@ VALUES (
     $1,
     $2
 )
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
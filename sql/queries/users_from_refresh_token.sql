@@ -1,5 +1,5 @@
 -- name: GetUserFromRefreshToken :one
-SELECT id, email, created_at, updated_at
+SELECT id, email, created_at, updated_at, account_state, suspended_until
 FROM users
 WHERE id = (
     SELECT user_id FROM refresh_tokens WHERE token = $1
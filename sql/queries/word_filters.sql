@@ -0,0 +1,45 @@
+-- name: GetWordFilters :many
+SELECT * FROM word_filters
+ORDER BY word ASC;
+
+-- name: CreateWordFilter :one
+INSERT INTO word_filters (id, created_at, updated_at, word, action)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2
+)
+ON CONFLICT (word) DO NOTHING
+RETURNING *;
+
+-- name: UpdateWordFilterAction :one
+UPDATE word_filters
+SET action = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: DeleteWordFilter :execrows
+DELETE FROM word_filters
+WHERE id = $1;
+
+-- name: FlagChirp :exec
+INSERT INTO flagged_chirps (chirp_id, created_at, words)
+VALUES ($1, NOW(), $2)
+ON CONFLICT (chirp_id) DO UPDATE SET words = EXCLUDED.words, created_at = EXCLUDED.created_at;
+
+-- name: GetFlaggedChirps :many
+SELECT sqlc.embed(chirps), flagged_chirps.words, flagged_chirps.created_at AS flagged_at
+FROM flagged_chirps
+JOIN chirps ON chirps.id = flagged_chirps.chirp_id
+WHERE (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (flagged_chirps.created_at, flagged_chirps.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY flagged_chirps.created_at DESC, flagged_chirps.chirp_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: UnflagChirp :exec
+DELETE FROM flagged_chirps
+WHERE chirp_id = $1;
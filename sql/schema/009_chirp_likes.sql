@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE chirp_likes (
+    chirp_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id),
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX chirp_likes_chirp_id_created_at_user_id_idx ON chirp_likes (chirp_id, created_at, user_id);
+CREATE INDEX chirp_likes_user_id_idx ON chirp_likes (user_id);
+
+ALTER TABLE chirps
+ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN like_count;
+
+DROP TABLE chirp_likes;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE word_filters (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    word TEXT UNIQUE NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'reject', 'flag'))
+);
+
+INSERT INTO word_filters (id, created_at, updated_at, word, action)
+VALUES
+    (gen_random_uuid(), NOW(), NOW(), 'kerfuffle', 'mask'),
+    (gen_random_uuid(), NOW(), NOW(), 'sharbert', 'mask'),
+    (gen_random_uuid(), NOW(), NOW(), 'fornax', 'mask');
+
+CREATE TABLE flagged_chirps (
+    chirp_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    words TEXT[] NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX flagged_chirps_created_at_chirp_id_idx ON flagged_chirps (created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE flagged_chirps;
+DROP TABLE word_filters;
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN deleted_at TIMESTAMP;
+
+-- Tombstones are what's left of deleted chirps, so they count as deleted too.
+UPDATE chirps
+SET deleted_at = tombstoned_at
+WHERE tombstoned_at IS NOT NULL;
+
+CREATE INDEX chirps_trash_idx ON chirps (user_id, deleted_at, id)
+WHERE deleted_at IS NOT NULL AND tombstoned_at IS NULL;
+CREATE INDEX chirps_deleted_at_idx ON chirps (deleted_at)
+WHERE deleted_at IS NOT NULL AND tombstoned_at IS NULL;
+
+-- +goose Down
+DROP INDEX chirps_deleted_at_idx;
+DROP INDEX chirps_trash_idx;
+
+ALTER TABLE chirps
+DROP COLUMN deleted_at;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Pending chirps live apart from chirps so they can't leak into any read path.
+-- parent_chirp_id has no foreign key: if the parent goes away before the
+-- chirp is due, publishing fails and the author is told why.
+CREATE TABLE scheduled_chirps (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    parent_chirp_id UUID,
+    flagged_words TEXT[] NOT NULL,
+    publish_at TIMESTAMP NOT NULL,
+    publish_error TEXT,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX scheduled_chirps_due_idx ON scheduled_chirps (publish_at)
+WHERE publish_error IS NULL;
+CREATE INDEX scheduled_chirps_user_id_publish_at_id_idx ON scheduled_chirps (user_id, publish_at, id);
+
+-- +goose Down
+DROP TABLE scheduled_chirps;
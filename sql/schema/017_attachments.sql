@@ -0,0 +1,29 @@
+-- +goose Up
+-- Uploads start out unattached. Once attached_at is set an attachment can't
+-- be moved to another chirp, even after its chirp is gone and chirp_id is
+-- cleared; unattached rows are garbage-collected along with their files.
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    chirp_id UUID,
+    position INT NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL,
+    size_bytes INT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    thumbnail_content_type TEXT NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    attached_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE SET NULL
+);
+
+CREATE INDEX attachments_chirp_id_position_idx ON attachments (chirp_id, position);
+CREATE INDEX attachments_orphaned_idx ON attachments (created_at)
+WHERE chirp_id IS NULL;
+
+-- +goose Down
+DROP TABLE attachments;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE link_previews (
+    chirp_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    url TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    image_url TEXT NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE link_previews;
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE polls (
+    chirp_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+-- vote_count is kept alongside the votes so tallies don't need counting on
+-- every read, and so results stay as they were when the poll closed.
+CREATE TABLE poll_options (
+    chirp_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    vote_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (chirp_id, position),
+    FOREIGN KEY (chirp_id) REFERENCES polls(chirp_id) ON DELETE CASCADE
+);
+
+CREATE TABLE poll_votes (
+    chirp_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id),
+    FOREIGN KEY (chirp_id, position) REFERENCES poll_options(chirp_id, position) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX poll_votes_user_id_idx ON poll_votes (user_id);
+
+-- +goose Down
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
@@ -0,0 +1,58 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'followers', 'private'));
+
+-- Users a followers-only or private chirp is addressed to. They can see the
+-- chirp whatever its visibility.
+CREATE TABLE chirp_mentions (
+    chirp_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    PRIMARY KEY (chirp_id, user_id),
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX chirp_mentions_user_id_idx ON chirp_mentions (user_id);
+
+-- Every read path checks visibility through this function so the rule lives
+-- in one place. viewer_id is NULL for anonymous requests. Followers-only
+-- chirps are limited to their author and mentions until there are follows.
+-- +goose StatementBegin
+CREATE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT $3 IN ('public', 'unlisted')
+        OR COALESCE($2 = $4, false)
+        OR EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = $1 AND chirp_mentions.user_id = $4
+        );
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE scheduled_chirps
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'followers', 'private')),
+ADD COLUMN mentions UUID[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE drafts
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'followers', 'private')),
+ADD COLUMN mentions UUID[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE drafts
+DROP COLUMN mentions,
+DROP COLUMN visibility;
+
+ALTER TABLE scheduled_chirps
+DROP COLUMN mentions,
+DROP COLUMN visibility;
+
+DROP FUNCTION chirp_visible_to;
+DROP TABLE chirp_mentions;
+
+ALTER TABLE chirps
+DROP COLUMN visibility;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Ephemeral chirps stop showing up as soon as expires_at passes and are
+-- deleted for good by the reaper shortly after.
+ALTER TABLE chirps
+ADD COLUMN expires_at TIMESTAMP;
+
+CREATE INDEX chirps_expires_at_idx ON chirps (expires_at)
+WHERE expires_at IS NOT NULL AND tombstoned_at IS NULL;
+
+-- +goose Down
+DROP INDEX chirps_expires_at_idx;
+
+ALTER TABLE chirps
+DROP COLUMN expires_at;
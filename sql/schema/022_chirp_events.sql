@@ -0,0 +1,63 @@
+-- +goose Up
+-- A short log of public chirps appearing and disappearing, for the live
+-- stream. Every server instance follows it through NOTIFY, and clients that
+-- reconnect resume from it with Last-Event-ID.
+CREATE TABLE chirp_events (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('created', 'deleted')),
+    chirp_id UUID NOT NULL,
+    user_id UUID NOT NULL
+);
+
+CREATE INDEX chirp_events_created_at_idx ON chirp_events (created_at);
+
+-- Recording events in a trigger catches every way a chirp is written:
+-- posting, publishing drafts and scheduled chirps, imports, deletes, the
+-- trash and the expiry reaper. The notification only says there's
+-- something new; listeners read the events from the table.
+-- +goose StatementBegin
+CREATE FUNCTION record_chirp_event() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    event_kind TEXT;
+    chirp chirps%ROWTYPE;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        chirp := NEW;
+        IF NEW.deleted_at IS NULL THEN
+            event_kind := 'created';
+        END IF;
+    ELSIF TG_OP = 'UPDATE' THEN
+        chirp := NEW;
+        IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+            event_kind := 'deleted';
+        ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+            event_kind := 'created';
+        END IF;
+    ELSE
+        chirp := OLD;
+        IF OLD.deleted_at IS NULL THEN
+            event_kind := 'deleted';
+        END IF;
+    END IF;
+
+    IF event_kind IS NOT NULL AND chirp.visibility = 'public' THEN
+        INSERT INTO chirp_events (created_at, kind, chirp_id, user_id)
+        VALUES (NOW(), event_kind, chirp.id, chirp.user_id);
+        PERFORM pg_notify('chirp_events', '');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER chirps_record_event
+AFTER INSERT OR UPDATE OF deleted_at OR DELETE ON chirps
+FOR EACH ROW EXECUTE FUNCTION record_chirp_event();
+
+-- +goose Down
+DROP TRIGGER chirps_record_event ON chirps;
+DROP FUNCTION record_chirp_event;
+DROP TABLE chirp_events;
@@ -0,0 +1,183 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN hidden_at TIMESTAMP;
+
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMP;
+
+-- A report keeps a copy of the chirp as it was reported, so the evidence
+-- survives edits and removal.
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    chirp_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_body TEXT NOT NULL,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'claimed', 'resolved')),
+    claimed_by TEXT,
+    claimed_at TIMESTAMP,
+    resolution TEXT
+        CHECK (resolution IN ('dismiss', 'hide', 'remove', 'suspend')),
+    resolved_by TEXT,
+    resolved_at TIMESTAMP,
+    UNIQUE (chirp_id, reporter_id)
+);
+
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at, id);
+
+-- Everything moderators do, in order. The chirp and user are copied rather
+-- than referenced so the log outlives them.
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    report_id UUID NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    moderator TEXT NOT NULL,
+    action TEXT NOT NULL
+        CHECK (action IN ('claim', 'dismiss', 'hide', 'remove', 'suspend', 'note')),
+    chirp_id UUID,
+    user_id UUID NOT NULL,
+    note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX moderation_actions_report_id_idx ON moderation_actions (report_id, created_at);
+
+-- Chirps hidden by moderators are only visible to their author.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT COALESCE($2 = $4, false)
+        OR (
+            NOT EXISTS (
+                SELECT 1 FROM chirps
+                WHERE chirps.id = $1 AND chirps.hidden_at IS NOT NULL
+            )
+            AND (
+                $3 IN ('public', 'unlisted')
+                OR EXISTS (
+                    SELECT 1 FROM chirp_mentions
+                    WHERE chirp_mentions.chirp_id = $1 AND chirp_mentions.user_id = $4
+                )
+            )
+        );
+$$;
+-- +goose StatementEnd
+
+-- Hiding a chirp takes it off the live stream like deleting it does.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_chirp_event() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    event_kind TEXT;
+    chirp chirps%ROWTYPE;
+    was_shown BOOLEAN;
+    is_shown BOOLEAN;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        chirp := NEW;
+        was_shown := false;
+        is_shown := NEW.deleted_at IS NULL AND NEW.hidden_at IS NULL;
+    ELSIF TG_OP = 'UPDATE' THEN
+        chirp := NEW;
+        was_shown := OLD.deleted_at IS NULL AND OLD.hidden_at IS NULL;
+        is_shown := NEW.deleted_at IS NULL AND NEW.hidden_at IS NULL;
+    ELSE
+        chirp := OLD;
+        was_shown := OLD.deleted_at IS NULL AND OLD.hidden_at IS NULL;
+        is_shown := false;
+    END IF;
+
+    IF is_shown AND NOT was_shown THEN
+        event_kind := 'created';
+    ELSIF was_shown AND NOT is_shown THEN
+        event_kind := 'deleted';
+    END IF;
+
+    IF event_kind IS NOT NULL AND chirp.visibility = 'public' THEN
+        INSERT INTO chirp_events (created_at, kind, chirp_id, user_id)
+        VALUES (NOW(), event_kind, chirp.id, chirp.user_id);
+        PERFORM pg_notify('chirp_events', '');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+DROP TRIGGER chirps_record_event ON chirps;
+CREATE TRIGGER chirps_record_event
+AFTER INSERT OR UPDATE OF deleted_at, hidden_at OR DELETE ON chirps
+FOR EACH ROW EXECUTE FUNCTION record_chirp_event();
+
+-- +goose Down
+DROP TRIGGER chirps_record_event ON chirps;
+CREATE TRIGGER chirps_record_event
+AFTER INSERT OR UPDATE OF deleted_at OR DELETE ON chirps
+FOR EACH ROW EXECUTE FUNCTION record_chirp_event();
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_chirp_event() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    event_kind TEXT;
+    chirp chirps%ROWTYPE;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        chirp := NEW;
+        IF NEW.deleted_at IS NULL THEN
+            event_kind := 'created';
+        END IF;
+    ELSIF TG_OP = 'UPDATE' THEN
+        chirp := NEW;
+        IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+            event_kind := 'deleted';
+        ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+            event_kind := 'created';
+        END IF;
+    ELSE
+        chirp := OLD;
+        IF OLD.deleted_at IS NULL THEN
+            event_kind := 'deleted';
+        END IF;
+    END IF;
+
+    IF event_kind IS NOT NULL AND chirp.visibility = 'public' THEN
+        INSERT INTO chirp_events (created_at, kind, chirp_id, user_id)
+        VALUES (NOW(), event_kind, chirp.id, chirp.user_id);
+        PERFORM pg_notify('chirp_events', '');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT $3 IN ('public', 'unlisted')
+        OR COALESCE($2 = $4, false)
+        OR EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = $1 AND chirp_mentions.user_id = $4
+        );
+$$;
+-- +goose StatementEnd
+
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+
+ALTER TABLE users
+DROP COLUMN suspended_at;
+
+ALTER TABLE chirps
+DROP COLUMN hidden_at;
@@ -0,0 +1,178 @@
+-- +goose Up
+-- Suspended accounts can't log in or use their tokens until suspended_until,
+-- or for good when it's NULL. Shadow-banned accounts carry on as normal, but
+-- nobody else sees what they post.
+ALTER TABLE users
+ADD COLUMN account_state TEXT NOT NULL DEFAULT 'active'
+    CHECK (account_state IN ('active', 'suspended', 'shadow_banned')),
+ADD COLUMN suspended_until TIMESTAMP,
+ADD COLUMN state_reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN state_changed_at TIMESTAMP;
+
+UPDATE users
+SET account_state = 'suspended', state_changed_at = suspended_at
+WHERE suspended_at IS NOT NULL;
+
+ALTER TABLE users
+DROP COLUMN suspended_at;
+
+CREATE TABLE account_state_changes (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    moderator TEXT NOT NULL,
+    account_state TEXT NOT NULL,
+    suspended_until TIMESTAMP,
+    reason TEXT NOT NULL
+);
+
+CREATE INDEX account_state_changes_user_id_idx ON account_state_changes (user_id, created_at);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT COALESCE($2 = $4, false)
+        OR (
+            NOT EXISTS (
+                SELECT 1 FROM chirps
+                WHERE chirps.id = $1 AND chirps.hidden_at IS NOT NULL
+            )
+            AND NOT EXISTS (
+                SELECT 1 FROM users
+                WHERE users.id = $2 AND users.account_state = 'shadow_banned'
+            )
+            AND (
+                $3 IN ('public', 'unlisted')
+                OR EXISTS (
+                    SELECT 1 FROM chirp_mentions
+                    WHERE chirp_mentions.chirp_id = $1 AND chirp_mentions.user_id = $4
+                )
+            )
+        );
+$$;
+-- +goose StatementEnd
+
+-- Shadow-banned chirps stay off the live stream.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_chirp_event() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    event_kind TEXT;
+    chirp chirps%ROWTYPE;
+    was_shown BOOLEAN;
+    is_shown BOOLEAN;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        chirp := NEW;
+        was_shown := false;
+        is_shown := NEW.deleted_at IS NULL AND NEW.hidden_at IS NULL;
+    ELSIF TG_OP = 'UPDATE' THEN
+        chirp := NEW;
+        was_shown := OLD.deleted_at IS NULL AND OLD.hidden_at IS NULL;
+        is_shown := NEW.deleted_at IS NULL AND NEW.hidden_at IS NULL;
+    ELSE
+        chirp := OLD;
+        was_shown := OLD.deleted_at IS NULL AND OLD.hidden_at IS NULL;
+        is_shown := false;
+    END IF;
+
+    IF is_shown AND NOT was_shown THEN
+        event_kind := 'created';
+    ELSIF was_shown AND NOT is_shown THEN
+        event_kind := 'deleted';
+    END IF;
+
+    IF event_kind IS NOT NULL AND chirp.visibility = 'public'
+        AND NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirp.user_id AND users.account_state = 'shadow_banned'
+        ) THEN
+        INSERT INTO chirp_events (created_at, kind, chirp_id, user_id)
+        VALUES (NOW(), event_kind, chirp.id, chirp.user_id);
+        PERFORM pg_notify('chirp_events', '');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_chirp_event() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    event_kind TEXT;
+    chirp chirps%ROWTYPE;
+    was_shown BOOLEAN;
+    is_shown BOOLEAN;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        chirp := NEW;
+        was_shown := false;
+        is_shown := NEW.deleted_at IS NULL AND NEW.hidden_at IS NULL;
+    ELSIF TG_OP = 'UPDATE' THEN
+        chirp := NEW;
+        was_shown := OLD.deleted_at IS NULL AND OLD.hidden_at IS NULL;
+        is_shown := NEW.deleted_at IS NULL AND NEW.hidden_at IS NULL;
+    ELSE
+        chirp := OLD;
+        was_shown := OLD.deleted_at IS NULL AND OLD.hidden_at IS NULL;
+        is_shown := false;
+    END IF;
+
+    IF is_shown AND NOT was_shown THEN
+        event_kind := 'created';
+    ELSIF was_shown AND NOT is_shown THEN
+        event_kind := 'deleted';
+    END IF;
+
+    IF event_kind IS NOT NULL AND chirp.visibility = 'public' THEN
+        INSERT INTO chirp_events (created_at, kind, chirp_id, user_id)
+        VALUES (NOW(), event_kind, chirp.id, chirp.user_id);
+        PERFORM pg_notify('chirp_events', '');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT COALESCE($2 = $4, false)
+        OR (
+            NOT EXISTS (
+                SELECT 1 FROM chirps
+                WHERE chirps.id = $1 AND chirps.hidden_at IS NOT NULL
+            )
+            AND (
+                $3 IN ('public', 'unlisted')
+                OR EXISTS (
+                    SELECT 1 FROM chirp_mentions
+                    WHERE chirp_mentions.chirp_id = $1 AND chirp_mentions.user_id = $4
+                )
+            )
+        );
+$$;
+-- +goose StatementEnd
+
+DROP TABLE account_state_changes;
+
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMP;
+
+UPDATE users
+SET suspended_at = COALESCE(state_changed_at, NOW())
+WHERE account_state = 'suspended';
+
+ALTER TABLE users
+DROP COLUMN account_state,
+DROP COLUMN suspended_until,
+DROP COLUMN state_reason,
+DROP COLUMN state_changed_at;
@@ -0,0 +1,104 @@
+-- +goose Up
+CREATE TABLE follows (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX follows_follower_id_created_at_idx ON follows (follower_id, created_at, followee_id);
+CREATE INDEX follows_followee_id_created_at_idx ON follows (followee_id, created_at, follower_id);
+
+ALTER TABLE users
+ADD COLUMN follower_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN following_count INTEGER NOT NULL DEFAULT 0;
+
+-- The counts are kept by a trigger rather than by the handlers so that
+-- follows removed by a cascading user delete are taken off too. Updates
+-- aimed at the user being deleted find no row and do nothing.
+-- +goose StatementBegin
+CREATE FUNCTION count_follow() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET follower_count = follower_count + 1 WHERE id = NEW.followee_id;
+        UPDATE users SET following_count = following_count + 1 WHERE id = NEW.follower_id;
+    ELSE
+        UPDATE users SET follower_count = GREATEST(follower_count - 1, 0) WHERE id = OLD.followee_id;
+        UPDATE users SET following_count = GREATEST(following_count - 1, 0) WHERE id = OLD.follower_id;
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER follows_count
+AFTER INSERT OR DELETE ON follows
+FOR EACH ROW EXECUTE FUNCTION count_follow();
+
+-- Followers-only chirps now reach their author's followers.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT COALESCE($2 = $4, false)
+        OR (
+            NOT EXISTS (
+                SELECT 1 FROM chirps
+                WHERE chirps.id = $1 AND chirps.hidden_at IS NOT NULL
+            )
+            AND NOT EXISTS (
+                SELECT 1 FROM users
+                WHERE users.id = $2 AND users.account_state = 'shadow_banned'
+            )
+            AND (
+                $3 IN ('public', 'unlisted')
+                OR ($3 = 'followers' AND EXISTS (
+                    SELECT 1 FROM follows
+                    WHERE follows.follower_id = $4 AND follows.followee_id = $2
+                ))
+                OR EXISTS (
+                    SELECT 1 FROM chirp_mentions
+                    WHERE chirp_mentions.chirp_id = $1 AND chirp_mentions.user_id = $4
+                )
+            )
+        );
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT COALESCE($2 = $4, false)
+        OR (
+            NOT EXISTS (
+                SELECT 1 FROM chirps
+                WHERE chirps.id = $1 AND chirps.hidden_at IS NOT NULL
+            )
+            AND NOT EXISTS (
+                SELECT 1 FROM users
+                WHERE users.id = $2 AND users.account_state = 'shadow_banned'
+            )
+            AND (
+                $3 IN ('public', 'unlisted')
+                OR EXISTS (
+                    SELECT 1 FROM chirp_mentions
+                    WHERE chirp_mentions.chirp_id = $1 AND chirp_mentions.user_id = $4
+                )
+            )
+        );
+$$;
+-- +goose StatementEnd
+
+DROP TABLE follows;
+DROP FUNCTION count_follow();
+
+ALTER TABLE users
+DROP COLUMN follower_count,
+DROP COLUMN following_count;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Home timelines, written when a chirp is posted: one row per follower of
+-- its author. Authors with very many followers are skipped and merged in
+-- when a timeline is read instead.
+CREATE TABLE timeline_entries (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- The chirp's created_at, copied so timelines page on this table alone.
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX timeline_entries_user_id_created_at_chirp_id_idx ON timeline_entries (user_id, created_at, chirp_id);
+CREATE INDEX timeline_entries_author_id_idx ON timeline_entries (author_id, user_id);
+
+-- +goose Down
+DROP TABLE timeline_entries;
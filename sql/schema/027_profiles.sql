@@ -0,0 +1,20 @@
+-- +goose Up
+-- Handles are optional until a user picks one, and unique ignoring case.
+ALTER TABLE users
+ADD COLUMN handle TEXT,
+ADD COLUMN display_name TEXT NOT NULL DEFAULT '',
+ADD COLUMN bio TEXT NOT NULL DEFAULT '',
+ADD COLUMN website TEXT NOT NULL DEFAULT '',
+ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX users_handle_idx ON users (lower(handle));
+
+-- +goose Down
+DROP INDEX users_handle_idx;
+
+ALTER TABLE users
+DROP COLUMN handle,
+DROP COLUMN display_name,
+DROP COLUMN bio,
+DROP COLUMN website,
+DROP COLUMN avatar_url;
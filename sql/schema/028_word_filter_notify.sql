@@ -0,0 +1,21 @@
+-- +goose Up
+-- Every instance caches the compiled word filter. Announcing changes lets
+-- the others drop theirs instead of filtering with a stale list.
+-- +goose StatementBegin
+CREATE FUNCTION notify_word_filters_changed() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    PERFORM pg_notify('word_filters', '');
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER word_filters_notify
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON word_filters
+FOR EACH STATEMENT EXECUTE FUNCTION notify_word_filters_changed();
+
+-- +goose Down
+DROP TRIGGER word_filters_notify ON word_filters;
+DROP FUNCTION notify_word_filters_changed;
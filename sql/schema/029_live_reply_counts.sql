@@ -0,0 +1,16 @@
+-- +goose Up
+-- Replies in the trash no longer count towards their parent's reply_count,
+-- matching what the reply list shows.
+UPDATE chirps
+SET reply_count = (
+    SELECT count(*) FROM chirps replies
+    WHERE replies.parent_chirp_id = chirps.id
+        AND replies.deleted_at IS NULL
+);
+
+-- +goose Down
+UPDATE chirps
+SET reply_count = (
+    SELECT count(*) FROM chirps replies
+    WHERE replies.parent_chirp_id = chirps.id
+);
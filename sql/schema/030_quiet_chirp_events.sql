@@ -0,0 +1,16 @@
+-- +goose Up
+-- Transactions that write chirps nobody is waiting to see, like archive
+-- imports, set chirpy.quiet_chirp_events so they don't flood the live
+-- stream. The setting is local to the transaction that sets it.
+DROP TRIGGER chirps_record_event ON chirps;
+CREATE TRIGGER chirps_record_event
+AFTER INSERT OR UPDATE OF deleted_at, hidden_at OR DELETE ON chirps
+FOR EACH ROW
+WHEN (current_setting('chirpy.quiet_chirp_events', true) IS DISTINCT FROM 'on')
+EXECUTE FUNCTION record_chirp_event();
+
+-- +goose Down
+DROP TRIGGER chirps_record_event ON chirps;
+CREATE TRIGGER chirps_record_event
+AFTER INSERT OR UPDATE OF deleted_at, hidden_at OR DELETE ON chirps
+FOR EACH ROW EXECUTE FUNCTION record_chirp_event();
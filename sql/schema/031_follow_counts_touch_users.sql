@@ -0,0 +1,37 @@
+-- +goose Up
+-- Follower counts are part of the profile embedded in chirps, so changing
+-- them moves the user's updated_at like any other profile change.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION count_follow() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET follower_count = follower_count + 1, updated_at = NOW() WHERE id = NEW.followee_id;
+        UPDATE users SET following_count = following_count + 1, updated_at = NOW() WHERE id = NEW.follower_id;
+    ELSE
+        UPDATE users SET follower_count = GREATEST(follower_count - 1, 0), updated_at = NOW() WHERE id = OLD.followee_id;
+        UPDATE users SET following_count = GREATEST(following_count - 1, 0), updated_at = NOW() WHERE id = OLD.follower_id;
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION count_follow() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET follower_count = follower_count + 1 WHERE id = NEW.followee_id;
+        UPDATE users SET following_count = following_count + 1 WHERE id = NEW.follower_id;
+    ELSE
+        UPDATE users SET follower_count = GREATEST(follower_count - 1, 0) WHERE id = OLD.followee_id;
+        UPDATE users SET following_count = GREATEST(following_count - 1, 0) WHERE id = OLD.follower_id;
+    END IF;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
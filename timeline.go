@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Home timelines are a hybrid. A chirp is copied into the timeline of each
+// of its author's followers when it's posted, unless the author has more
+// than timelineFanOutMaxFollowers followers: writing to that many timelines
+// costs more than finding those authors' chirps when a timeline is read.
+// Reads merge the stored timeline with the reader's own chirps and with
+// chirps by the big accounts they follow.
+//
+// Authors who drop below the limit are missing from stored timelines for
+// whatever they posted above it until the timelines are rebuilt.
+const (
+	timelineFanOutMaxFollowers = 10000
+	// How far back a timeline reaches for each author when someone new is
+	// followed or the timeline is rebuilt.
+	timelineBackfillPerAuthor = 200
+)
+
+// fanOutChirp adds a new chirp to its author's followers' timelines.
+// Private chirps never go into timelines; everything else is checked
+// against the reader when the timeline is read, so a chirp that's later
+// hidden or deleted drops out on its own.
+func fanOutChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	if chirp.Visibility == visibilityPrivate {
+		return nil
+	}
+	return q.FanOutChirp(ctx, database.FanOutChirpParams{
+		ChirpID:      chirp.ID,
+		CreatedAt:    chirp.CreatedAt,
+		AuthorID:     chirp.UserID,
+		MaxFollowers: timelineFanOutMaxFollowers,
+	})
+}
+
+// backfillTimeline copies recent chirps by authorID, or by everyone the
+// user follows when authorID is uuid.Nil, into the user's timeline.
+func backfillTimeline(ctx context.Context, q *database.Queries, userID, authorID uuid.UUID) (int64, error) {
+	return q.BackfillTimeline(ctx, database.BackfillTimelineParams{
+		PerAuthor:    timelineBackfillPerAuthor,
+		UserID:       userID,
+		AuthorID:     uuid.NullUUID{UUID: authorID, Valid: authorID != uuid.Nil},
+		MaxFollowers: timelineFanOutMaxFollowers,
+	})
+}
+
+// getTimelineHandler returns the caller's home timeline: chirps by the
+// accounts they follow and by themselves, newest first.
+func (cfg *apiConfig) getTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := page.cursorArgs()
+	dbChirps, err := cfg.dbQueries.GetTimeline(r.Context(), database.GetTimelineParams{
+		UserID:          validatedUserID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+		MaxFollowers:    timelineFanOutMaxFollowers,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve timeline", err)
+		return
+	}
+
+	dbChirps, next, _ := paginate(dbChirps, page, chirpCursor)
+	chirps := []Chirp{}
+	for _, dbChirp := range dbChirps {
+		chirps = append(chirps, chirpFromDB(dbChirp))
+	}
+	if err := cfg.decorateChirps(r, chirps); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithCacheableJSON(w, r, chirps, latestUpdate(chirps))
+}
+
+// rebuildTimelinesHandler throws away stored timelines and fills them in
+// again from the follow graph: one user's with user_id, otherwise a batch of
+// up to limit users in ID order. Rebuilding everyone means following the
+// next link until done is true; each batch starts after the user ID in
+// after. It is safe to re-run, and readers only ever see a whole timeline.
+// The -rebuild-timelines flag does the whole loop from the command line.
+func (cfg *apiConfig) rebuildTimelinesHandler(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		UsersRebuilt   int   `json:"users_rebuilt"`
+		EntriesWritten int64 `json:"entries_written"`
+		Done           bool  `json:"done"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	if idString := query.Get("user_id"); idString != "" {
+		userID, err := uuid.Parse(idString)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid user_id", err)
+			return
+		}
+		if _, err := cfg.dbQueries.GetUserByID(r.Context(), userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "User not found", nil)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+			return
+		}
+		written, err := cfg.rebuildTimeline(r.Context(), userID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't rebuild timeline", err)
+			return
+		}
+		respondWithJSON(w, http.StatusOK, response{UsersRebuilt: 1, EntriesWritten: written, Done: true})
+		return
+	}
+	limit, err := parseLimit(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	params := database.GetUserIDsParams{Limit: limit + 1}
+	if after := query.Get("after"); after != "" {
+		afterID, err := uuid.Parse(after)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid after", err)
+			return
+		}
+		params.After = uuid.NullUUID{UUID: afterID, Valid: true}
+	}
+
+	batch, err := cfg.dbQueries.GetUserIDs(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve users", err)
+		return
+	}
+	resp := response{Done: len(batch) <= int(limit)}
+	if !resp.Done {
+		batch = batch[:limit]
+	}
+	started := time.Now()
+	for _, userID := range batch {
+		written, err := cfg.rebuildTimeline(r.Context(), userID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't rebuild timeline", err)
+			return
+		}
+		resp.UsersRebuilt++
+		resp.EntriesWritten += written
+	}
+	log.Printf("Rebuilt %d timelines in %s", resp.UsersRebuilt, time.Since(started).Round(time.Millisecond))
+
+	if !resp.Done {
+		setPageLinks(w, r, batch[len(batch)-1].String(), "")
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// rebuildAllTimelines is the -rebuild-timelines command: it rebuilds every
+// user's timeline in batches, the same way as following the admin
+// endpoint's next links to the end, without a request timeout to outlast.
+func (cfg *apiConfig) rebuildAllTimelines(ctx context.Context) error {
+	params := database.GetUserIDsParams{Limit: maxPageLimit}
+	users, entries := 0, int64(0)
+	for {
+		batch, err := cfg.dbQueries.GetUserIDs(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, userID := range batch {
+			written, err := cfg.rebuildTimeline(ctx, userID)
+			if err != nil {
+				log.Printf("Error rebuilding timeline of user %s: %s", userID, err)
+				return err
+			}
+			users++
+			entries += written
+		}
+		if len(batch) < int(params.Limit) {
+			break
+		}
+		params.After = uuid.NullUUID{UUID: batch[len(batch)-1], Valid: true}
+		log.Printf("Rebuilt %d timelines so far, up to user %s", users, params.After.UUID)
+	}
+	log.Printf("Rebuilt %d timelines with %d entries", users, entries)
+	return nil
+}
+
+func (cfg *apiConfig) rebuildTimeline(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	err = qtx.ClearTimeline(ctx, database.ClearTimelineParams{UserID: userID})
+	if err != nil {
+		return 0, err
+	}
+	written, err := backfillTimeline(ctx, qtx, userID, uuid.Nil)
+	if err != nil {
+		return 0, err
+	}
+	return written, tx.Commit()
+}
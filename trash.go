@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	trashPurgeInterval    = time.Hour
+	trashPurgeBatchSize   = 500
+)
+
+func (cfg *apiConfig) getTrashHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorDeletedAt, cursorID := page.cursorArgs()
+	dbChirps, err := cfg.dbQueries.GetTrashedChirps(r.Context(), database.GetTrashedChirpsParams{
+		UserID:          validatedUserID,
+		DeletedSince:    time.Now().UTC().Add(-cfg.trashRetention),
+		CursorDeletedAt: cursorDeletedAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve trash", err)
+		return
+	}
+
+	dbChirps, next, _ := paginate(dbChirps, page, func(chirp database.Chirp) pageCursor {
+		return pageCursor{CreatedAt: chirp.DeletedAt.Time, ID: chirp.ID}
+	})
+	chirps := []Chirp{}
+	for _, dbChirp := range dbChirps {
+		chirps = append(chirps, fullChirpFromDB(dbChirp))
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, chirps)
+}
+
+func (cfg *apiConfig) restoreChirpHandler(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing Authorization header", err)
+		return
+	}
+	validatedUserID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := qtx.ChirpsByIdIncludingDeletedForUpdate(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if chirp.UserID != validatedUserID {
+		respondWithError(w, http.StatusForbidden, "You cannot restore someone elses chirp", nil)
+		return
+	}
+	if !chirp.DeletedAt.Valid {
+		respondWithError(w, http.StatusConflict, "Chirp is not in the trash", nil)
+		return
+	}
+	if chirp.TombstonedAt.Valid || chirpExpired(chirp) || chirp.DeletedAt.Time.Before(time.Now().UTC().Add(-cfg.trashRetention)) {
+		respondWithError(w, http.StatusGone, "Chirp can no longer be restored", nil)
+		return
+	}
+
+	restored, err := qtx.RestoreChirp(r.Context(), chirp.ID)
+	if err == nil {
+		err = adjustChirpReferences(r.Context(), qtx, restored, 1)
+	}
+	if err == nil && restored.ParentChirpID.Valid {
+		err = qtx.IncrementReplyCount(r.Context(), restored.ParentChirpID.UUID)
+	}
+	if err == nil {
+		err = tagChirp(r.Context(), qtx, restored)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirpFromDB(restored))
+}
+
+// adminGetChirpHandler shows a chirp as stored, deleted or not, so
+// moderators can look into what was actually posted.
+func (cfg *apiConfig) adminGetChirpHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	idString := r.PathValue("chirpID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+	chirp, err := cfg.dbQueries.ChirpsByIdIncludingDeleted(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, fullChirpFromDB(chirp))
+}
+
+// purgeTrashLoop empties expired trash every interval until ctx is done.
+func (cfg *apiConfig) purgeTrashLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := cfg.purgeTrash(ctx)
+		if err != nil {
+			log.Printf("Error purging trash: %s", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d chirps from the trash", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeTrash permanently removes chirps that have been in the trash longer
+// than the retention window. Chirps that still have replies are tombstoned
+// rather than deleted so the thread keeps its shape.
+func (cfg *apiConfig) purgeTrash(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		n, err := cfg.purgeTrashBatch(ctx)
+		purged += n
+		if err != nil || n < trashPurgeBatchSize {
+			return purged, err
+		}
+	}
+}
+
+func (cfg *apiConfig) purgeTrashBatch(ctx context.Context) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	expired, err := qtx.GetExpiredTrash(ctx, database.GetExpiredTrashParams{
+		DeletedBefore: time.Now().UTC().Add(-cfg.trashRetention),
+		Limit:         trashPurgeBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, chirp := range expired {
+		// Purging an earlier reply may have removed this chirp's last one.
+		hasReplies, err := chirpHasReplies(ctx, qtx, chirp.ID)
+		if err != nil {
+			return 0, err
+		}
+		if hasReplies {
+			err = tombstoneChirp(ctx, qtx, chirp.ID)
+		} else {
+			err = removeChirp(ctx, qtx, chirp)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
@@ -43,13 +43,7 @@ func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	respondWithJSON(w, http.StatusCreated, response{
-		User: User{
-			ID:        user.ID,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-			Email:     user.Email,
-			ChirpyRed: user.IsChirpyRed,
-		},
+		User: userFromDB(user),
 	})
 
 }
@@ -95,13 +89,7 @@ func (cfg *apiConfig) usersHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	respondWithJSON(w, http.StatusOK, response{
-		User: User{
-			ID:        updatedUser.ID,
-			CreatedAt: updatedUser.CreatedAt,
-			UpdatedAt: updatedUser.UpdatedAt,
-			Email:     updatedUser.Email,
-			ChirpyRed: updatedUser.IsChirpyRed,
-		},
+		User: userFromDB(updatedUser),
 	})
 
 }
@@ -150,3 +138,20 @@ func (cfg *apiConfig) userUpgradeHandler(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 
 }
+
+func userFromDB(user database.User) User {
+	return User{
+		ID:             user.ID,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		Email:          user.Email,
+		ChirpyRed:      user.IsChirpyRed,
+		FollowerCount:  user.FollowerCount,
+		FollowingCount: user.FollowingCount,
+		Handle:         nullStringPtr(user.Handle),
+		DisplayName:    user.DisplayName,
+		Bio:            user.Bio,
+		Website:        user.Website,
+		AvatarURL:      user.AvatarUrl,
+	}
+}
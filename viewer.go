@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/cygran/chirpy/internal/auth"
+	"github.com/google/uuid"
+)
+
+// viewerID returns the user making the request when it carries a valid
+// bearer token. Anonymous requests and bad tokens both report false, so
+// read endpoints can personalise responses without requiring a login.
+func (cfg *apiConfig) viewerID(r *http.Request) (uuid.UUID, bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Who can see a chirp:
+//   - public: everyone, and it's listed in feeds, hashtags and search.
+//   - unlisted: everyone with a link, but it's kept out of those listings.
+//   - followers: its author's followers and anyone it mentions.
+//   - private: only the people it mentions.
+//
+// The author can always see their own chirps, even after a moderator has
+// hidden them from everyone else. The rule itself lives in the
+// chirp_visible_to SQL function; canViewChirp only short-cuts the easy cases.
+const (
+	visibilityPublic    = "public"
+	visibilityUnlisted  = "unlisted"
+	visibilityFollowers = "followers"
+	visibilityPrivate   = "private"
+)
+
+const maxMentions = 20
+
+// chirpAudience is who a new chirp is for.
+type chirpAudience struct {
+	Visibility string
+	Mentions   []uuid.UUID
+}
+
+// parseAudience checks the visibility and mentions sent with a chirp. An
+// empty visibility means public. Mentions only widen the audience of
+// followers-only and private chirps, so they're refused elsewhere.
+func parseAudience(visibility string, mentions []uuid.UUID) (chirpAudience, error) {
+	switch visibility {
+	case "":
+		visibility = visibilityPublic
+	case visibilityPublic, visibilityUnlisted, visibilityFollowers, visibilityPrivate:
+	default:
+		return chirpAudience{}, errors.New("Visibility must be public, unlisted, followers or private")
+	}
+	if len(mentions) > 0 && (visibility == visibilityPublic || visibility == visibilityUnlisted) {
+		return chirpAudience{}, errors.New("Mentions are only allowed on followers-only and private chirps")
+	}
+	if len(mentions) > maxMentions {
+		return chirpAudience{}, errors.New("Too many mentions")
+	}
+	seen := make(map[uuid.UUID]bool, len(mentions))
+	unique := []uuid.UUID{}
+	for _, id := range mentions {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return chirpAudience{Visibility: visibility, Mentions: unique}, nil
+}
+
+// canViewChirp reports whether viewerID, or uuid.Nil for an anonymous
+// viewer, may see chirp.
+func canViewChirp(ctx context.Context, q *database.Queries, chirp database.Chirp, viewerID uuid.UUID) (bool, error) {
+	switch {
+	case viewerID != uuid.Nil && chirp.UserID == viewerID:
+		return true, nil
+	case chirp.HiddenAt.Valid:
+		return false, nil
+	case viewerID == uuid.Nil && chirp.Visibility != visibilityPublic && chirp.Visibility != visibilityUnlisted:
+		return false, nil
+	}
+	// Even public chirps need the database, to rule out shadow-banned
+	// authors.
+	return q.ChirpVisibleTo(ctx, database.ChirpVisibleToParams{
+		ViewerID: uuid.NullUUID{UUID: viewerID, Valid: true},
+		ID:       chirp.ID,
+	})
+}
+
+// viewableChirp loads a chirp for the request's viewer. Chirps the viewer
+// can't see are reported as sql.ErrNoRows, exactly like missing ones, so a
+// 404 doesn't reveal that they exist.
+func (cfg *apiConfig) viewableChirp(r *http.Request, q *database.Queries, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := q.ChirpsById(r.Context(), id)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	viewerID, _ := cfg.viewerID(r)
+	visible, err := canViewChirp(r.Context(), q, chirp, viewerID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if !visible {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// viewerParam is the viewer_id argument for visibility-aware queries.
+func viewerParam(viewerID uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: viewerID, Valid: viewerID != uuid.Nil}
+}
+
+// respondWithNotYourChirp answers a request to change someone else's chirp:
+// 403 if the caller can see the chirp, and 404 if they can't so they don't
+// learn it exists.
+func respondWithNotYourChirp(w http.ResponseWriter, r *http.Request, q *database.Queries, chirp database.Chirp, userID uuid.UUID, msg string) {
+	visible, err := canViewChirp(r.Context(), q, chirp, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Database query failed", err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+	respondWithError(w, http.StatusForbidden, msg, nil)
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cygran/chirpy/internal/database"
+	"github.com/cygran/chirpy/internal/wordfilter"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type WordFilter struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Word      string    `json:"word"`
+	Action    string    `json:"action"`
+}
+
+type FlaggedChirp struct {
+	Chirp     Chirp     `json:"chirp"`
+	Words     []string  `json:"words"`
+	FlaggedAt time.Time `json:"flagged_at"`
+}
+
+// wordFilterCache holds the compiled word filter until the word list
+// changes. Loading happens under the lock so an invalidation can never be
+// overwritten by a load that started before it.
+type wordFilterCache struct {
+	mu     sync.Mutex
+	filter *wordfilter.Filter
+}
+
+func (cfg *apiConfig) wordFilter(ctx context.Context) (*wordfilter.Filter, error) {
+	cache := &cfg.wordFilters
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.filter != nil {
+		return cache.filter, nil
+	}
+	dbFilters, err := cfg.dbQueries.GetWordFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]wordfilter.Rule, 0, len(dbFilters))
+	for _, dbFilter := range dbFilters {
+		rules = append(rules, wordfilter.Rule{
+			Word:   dbFilter.Word,
+			Action: wordfilter.Action(dbFilter.Action),
+		})
+	}
+	cache.filter = wordfilter.New(rules)
+	return cache.filter, nil
+}
+
+func (cfg *apiConfig) invalidateWordFilter() {
+	cfg.wordFilters.mu.Lock()
+	cfg.wordFilters.filter = nil
+	cfg.wordFilters.mu.Unlock()
+}
+
+// followWordFilterChanges drops the cached word filter whenever any
+// instance changes the word list, until ctx is done. Changes made while the
+// listener was reconnecting can't be told apart, so a reconnect drops the
+// cache too.
+func (cfg *apiConfig) followWordFilterChanges(ctx context.Context, dbURL string) {
+	listener := pq.NewListener(dbURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Word filter listener: %s", err)
+		}
+	})
+	defer listener.Close()
+	if err := listener.Listen("word_filters"); err != nil {
+		log.Printf("Error listening for word filter changes: %s", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.NotificationChannel():
+			// A nil notification means the connection was re-established.
+			cfg.invalidateWordFilter()
+		}
+	}
+}
+
+// flagChirp puts a chirp in the review queue when the word filter flagged it.
+func flagChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID, words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+	return q.FlagChirp(ctx, database.FlagChirpParams{
+		ChirpID: chirpID,
+		Words:   words,
+	})
+}
+
+func (cfg *apiConfig) getWordFiltersHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	dbFilters, err := cfg.dbQueries.GetWordFilters(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve word filters", err)
+		return
+	}
+	filters := []WordFilter{}
+	for _, dbFilter := range dbFilters {
+		filters = append(filters, wordFilterFromDB(dbFilter))
+	}
+	respondWithJSON(w, http.StatusOK, filters)
+}
+
+func (cfg *apiConfig) createWordFilterHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Word   string `json:"word"`
+		Action string `json:"action"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	word, err := wordfilter.Normalize(params.Word)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid word: "+err.Error(), err)
+		return
+	}
+	if params.Action == "" {
+		params.Action = string(wordfilter.ActionMask)
+	}
+	action, err := wordfilter.ParseAction(params.Action)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid action: "+err.Error(), err)
+		return
+	}
+
+	dbFilter, err := cfg.dbQueries.CreateWordFilter(r.Context(), database.CreateWordFilterParams{
+		Word:   word,
+		Action: string(action),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusConflict, "Word is already filtered", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create word filter", err)
+		return
+	}
+	cfg.invalidateWordFilter()
+
+	respondWithJSON(w, http.StatusCreated, wordFilterFromDB(dbFilter))
+}
+
+func (cfg *apiConfig) updateWordFilterHandler(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Action string `json:"action"`
+	}
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	idString := r.PathValue("filterID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid filter ID format", err)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+	action, err := wordfilter.ParseAction(params.Action)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid action: "+err.Error(), err)
+		return
+	}
+
+	dbFilter, err := cfg.dbQueries.UpdateWordFilterAction(r.Context(), database.UpdateWordFilterActionParams{
+		Action: string(action),
+		ID:     id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Word filter not found", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update word filter", err)
+		return
+	}
+	cfg.invalidateWordFilter()
+
+	respondWithJSON(w, http.StatusOK, wordFilterFromDB(dbFilter))
+}
+
+func (cfg *apiConfig) deleteWordFilterHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	idString := r.PathValue("filterID")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid filter ID format", err)
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteWordFilter(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete word filter", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Word filter not found", nil)
+		return
+	}
+	cfg.invalidateWordFilter()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) getFlaggedChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	page, err := parseForwardPageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := page.cursorArgs()
+	rows, err := cfg.dbQueries.GetFlaggedChirps(r.Context(), database.GetFlaggedChirpsParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve flagged chirps", err)
+		return
+	}
+
+	rows, next, _ := paginate(rows, page, func(row database.GetFlaggedChirpsRow) pageCursor {
+		return pageCursor{CreatedAt: row.FlaggedAt, ID: row.Chirp.ID}
+	})
+	flagged := []FlaggedChirp{}
+	for _, row := range rows {
+		flagged = append(flagged, FlaggedChirp{
+			Chirp:     fullChirpFromDB(row.Chirp),
+			Words:     row.Words,
+			FlaggedAt: row.FlaggedAt,
+		})
+	}
+
+	setPageLinks(w, r, next, "")
+	respondWithJSON(w, http.StatusOK, flagged)
+}
+
+func wordFilterFromDB(filter database.WordFilter) WordFilter {
+	return WordFilter{
+		ID:        filter.ID,
+		CreatedAt: filter.CreatedAt,
+		UpdatedAt: filter.UpdatedAt,
+		Word:      filter.Word,
+		Action:    filter.Action,
+	}
+}